@@ -1,15 +1,21 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
+// DefaultShutdownTimeout used when crawlers.shutdown-timeout is not set
+const DefaultShutdownTimeout = 30 * time.Second
+
 // ReadConfig reads config file
 func ReadConfig() (cfg Config, err error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("toml")
 	viper.AddConfigPath(".")
 	viper.AddConfigPath("..")
+	viper.SetDefault("crawlers.shutdown-timeout", DefaultShutdownTimeout)
 
 	err = viper.ReadInConfig()
 	if err != nil {
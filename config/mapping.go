@@ -1,11 +1,16 @@
 package config
 
+import "time"
+
 // Config app config
 type Config struct {
-	Db       DBConfig      `mapstructure:"db"`
-	Crawlers CrawlerConfig `mapstructure:"crawlers"`
-	Log      LogConfig     `mapstructure:"log"`
-	Queue    QueueConfig   `mapstructure:"queue"`
+	Db        DBConfig        `mapstructure:"db"`
+	Crawlers  CrawlerConfig   `mapstructure:"crawlers"`
+	Log       LogConfig       `mapstructure:"log"`
+	Queue     QueueConfig     `mapstructure:"queue"`
+	Scheduler SchedulerConfig `mapstructure:"scheduler"`
+	// Notifications opt-in alerts sent besides the below max price one
+	Notifications NotificationConfig `mapstructure:"notifications"`
 }
 
 // DBConfig database configs
@@ -19,10 +24,215 @@ type DBConfig struct {
 }
 
 type CrawlerConfig struct {
-	Amazon       string `mapstructure:"amazon"`
-	MercadoLivre string `mapstructure:"mercado-livre"`
-	Kabum        string `mapstructure:"kabum"`
-	NumCrawlers  int    `mapstructure:"num-crawlers"`
+	// NumCrawlers overall cap of crawlers running at once, shared by every
+	// crawler
+	NumCrawlers int `mapstructure:"num-crawlers"`
+	// DefaultCheckInterval used for products whose crawler has no default
+	// check interval of its own
+	DefaultCheckInterval time.Duration `mapstructure:"default-check-interval"`
+	Lease                LeaseConfig   `mapstructure:"lease"`
+	// ShutdownTimeout how long running crawlers may take to finish after a
+	// shutdown is requested before they are killed, defaults to 30s
+	ShutdownTimeout time.Duration `mapstructure:"shutdown-timeout"`
+	// Timeout default execution timeout of the crawlers that do not set their
+	// own. Zero means no timeout
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Retry default retry policy, crawlers may override its fields
+	Retry RetryConfig `mapstructure:"retry"`
+	// CircuitBreaker default circuit breaker, crawlers may override its fields
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit-breaker"`
+	// FallbackCrawler crawler of the products that have none and whose link
+	// is of no supported store, e.g. a native entry running the generic store.
+	// Empty skips them
+	FallbackCrawler string `mapstructure:"fallback-crawler"`
+	// HTTP client shared by the native crawlers
+	HTTP  HTTPConfig          `mapstructure:"http"`
+	Sites []CrawlerSiteConfig `mapstructure:"sites"`
+}
+
+// CrawlerSiteConfig a crawler of the registry. Args and Env ("KEY=value")
+// are templates rendered with the product Link and ID and the WorkingDir
+type CrawlerSiteConfig struct {
+	Name string `mapstructure:"name"`
+	// Type "exec" (default) runs Command, "native" the crawler written in Go
+	// registered under Store and "selector" reads the page with Selectors
+	Type string `mapstructure:"type"`
+	// Store native crawler run by the entry, defaults to Name
+	Store      string        `mapstructure:"store"`
+	Command    string        `mapstructure:"command"`
+	Args       []string      `mapstructure:"args"`
+	WorkingDir string        `mapstructure:"working-dir"`
+	Env        []string      `mapstructure:"env"`
+	Timeout    time.Duration `mapstructure:"timeout"`
+	// Concurrency cap of products of this crawler crawled at once, defaults
+	// to NumCrawlers
+	Concurrency int             `mapstructure:"concurrency"`
+	Enabled     *bool           `mapstructure:"enabled"`
+	RateLimit   RateLimitConfig `mapstructure:"rate-limit"`
+	// Mode "exec" (default) starts a process per product, "persistent" keeps
+	// Workers processes running and sends them the products through stdin
+	Mode string `mapstructure:"mode"`
+	// Workers persistent processes of this crawler, defaults to Concurrency
+	Workers int `mapstructure:"workers"`
+	// MaxRequests products handled by a persistent worker before it is
+	// restarted, 0 means no limit
+	MaxRequests int `mapstructure:"max-requests"`
+	// WorkerArgs args used to start a persistent worker, defaults to Args
+	WorkerArgs []string `mapstructure:"worker-args"`
+	// BatchSize products handed to a single crawler run, 0 or 1 disables
+	// batching
+	BatchSize int `mapstructure:"batch-size"`
+	// BatchArgs args used to crawl a batch of products, defaults to WorkerArgs
+	// and then Args
+	BatchArgs []string `mapstructure:"batch-args"`
+	// Retry overrides the fields set of the default retry policy
+	Retry RetryConfig `mapstructure:"retry"`
+	// CircuitBreaker overrides the fields set of the default circuit breaker
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit-breaker"`
+	// Selectors where a crawler of type "selector" finds the product data
+	Selectors SelectorConfig `mapstructure:"selectors"`
+	// OutputFormat how the crawler output is read: "auto" (default) takes the
+	// JSON protocol line or else the legacy repr, "legacy", "json", "kv"
+	// (key=value pairs) or "csv" (a row)
+	OutputFormat string `mapstructure:"output-format"`
+	// Domains link domains, subdomains included, of the products read by
+	// this crawler. Products without a crawler are assigned by them, and
+	// products of the crawler must have a link in them. Empty accepts any link
+	Domains []string `mapstructure:"domains"`
+}
+
+const (
+	CrawlerModeExec       = "exec"
+	CrawlerModePersistent = "persistent"
+)
+
+const (
+	CrawlerTypeExec     = "exec"
+	CrawlerTypeNative   = "native"
+	CrawlerTypeSelector = "selector"
+)
+
+// IsEnabled crawlers are enabled unless explicitly disabled
+func (c *CrawlerSiteConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// IsNative whether the crawler runs inside the orchestrator, written in Go or
+// declared with selectors, instead of as a command
+func (c *CrawlerSiteConfig) IsNative() bool {
+	return c.Type == CrawlerTypeNative || c.Type == CrawlerTypeSelector
+}
+
+// StoreName native crawler run by the entry
+func (c *CrawlerSiteConfig) StoreName() string {
+	if c.Store != "" {
+		return c.Store
+	}
+
+	return c.Name
+}
+
+// IsPersistent whether the crawler runs as long-lived worker processes
+func (c *CrawlerSiteConfig) IsPersistent() bool {
+	return c.Mode == CrawlerModePersistent
+}
+
+// RateLimitConfig politeness quotas of a crawler. Zero values disable the
+// corresponding limit. Usage is kept in memory, so DailyBudget counts the
+// requests of the running process only: it spans the scheduled runs in serve
+// mode but starts over on every one-shot run or restart
+type RateLimitConfig struct {
+	RequestsPerSecond float64       `mapstructure:"requests-per-second"`
+	Burst             int           `mapstructure:"burst"`
+	MinSpacing        time.Duration `mapstructure:"min-spacing"`
+	Jitter            time.Duration `mapstructure:"jitter"`
+	DailyBudget       int           `mapstructure:"daily-budget"`
+}
+
+// RetryConfig retry policy of the failed products. Zero values fall back to
+// the defaults
+type RetryConfig struct {
+	// MaxAttempts attempts per product, including the first one. 0 or 1
+	// disables retries
+	MaxAttempts    int           `mapstructure:"max-attempts"`
+	InitialBackoff time.Duration `mapstructure:"initial-backoff"`
+	MaxBackoff     time.Duration `mapstructure:"max-backoff"`
+	// RetryOn failure kinds retried, defaults to network and timeout
+	RetryOn []string `mapstructure:"retry-on"`
+	// SecondPass crawls once more, at the end of the run, the products whose
+	// retryable failures outlasted their attempts. Only read from
+	// [crawlers.retry]
+	SecondPass bool `mapstructure:"second-pass"`
+}
+
+// CircuitBreakerConfig stops crawling a site that keeps failing. It opens once
+// the failures reach FailureRatio of the last MinRequests requests and, after
+// CoolDown, lets a single trial request through before closing again
+type CircuitBreakerConfig struct {
+	// FailureRatio between 0 and 1, 0 disables the circuit breaker
+	FailureRatio float64       `mapstructure:"failure-ratio"`
+	MinRequests  int           `mapstructure:"min-requests"`
+	CoolDown     time.Duration `mapstructure:"cool-down"`
+}
+
+// SelectorConfig product page of a crawler of type "selector". Pages are HTML
+// unless Format is "json", for stores whose product links return JSON
+type SelectorConfig struct {
+	// URLPattern regular expression the product links must match, empty
+	// accepts any link
+	URLPattern string `mapstructure:"url-pattern"`
+	Format     string `mapstructure:"format"`
+	// PriceLocale "pt-BR" (default), with a comma as decimal separator, or
+	// "en-US"
+	PriceLocale string `mapstructure:"price-locale"`
+	// PriceInCents whether the prices read are already in cents rather than
+	// in reais, as some JSON APIs return them
+	PriceInCents  bool          `mapstructure:"price-in-cents"`
+	Currency      string        `mapstructure:"currency"`
+	Price         FieldSelector `mapstructure:"price"`
+	OriginalPrice FieldSelector `mapstructure:"original-price"`
+	Discount      FieldSelector `mapstructure:"discount"`
+	// Stock text telling whether the product can be bought. Empty when the
+	// element is missing, a schema.org availability or one of OutOfStockText
+	// are read as such, any other text as in stock
+	Stock          FieldSelector `mapstructure:"stock"`
+	OutOfStockText []string      `mapstructure:"out-of-stock-text"`
+}
+
+// FieldSelector where a value is in the page: the text, or attribute Attr, of
+// the first element matching CSS, then JSONPath ("product.offers.0.price")
+// inside it. JSON pages are read with JSONPath alone
+type FieldSelector struct {
+	CSS      string `mapstructure:"css"`
+	Attr     string `mapstructure:"attr"`
+	JSONPath string `mapstructure:"json-path"`
+}
+
+const (
+	SelectorFormatHTML = "html"
+	SelectorFormatJSON = "json"
+)
+
+// IsSet whether the field is read at all
+func (f *FieldSelector) IsSet() bool {
+	return f.CSS != "" || f.JSONPath != ""
+}
+
+// HTTPConfig requests made by the native crawlers. Zero values fall back to
+// the defaults
+type HTTPConfig struct {
+	UserAgent string `mapstructure:"user-agent"`
+	// Headers sent with every request, besides User-Agent
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+// LeaseConfig configs for sharing the catalog between several orchestrators.
+// Owner defaults to hostname-pid
+type LeaseConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Owner     string        `mapstructure:"owner"`
+	BatchSize int           `mapstructure:"batch-size"`
+	Duration  time.Duration `mapstructure:"duration"`
 }
 
 type LogConfig struct {
@@ -35,3 +245,20 @@ type LogConfig struct {
 type QueueConfig struct {
 	QueueName string `mapstructure:"queue-name"`
 }
+
+// SchedulerConfig configs for the serve (daemon) mode. Cron takes precedence
+// over Interval when both are set
+type SchedulerConfig struct {
+	Cron       string        `mapstructure:"cron"`
+	Interval   time.Duration `mapstructure:"interval"`
+	RunOnStart bool          `mapstructure:"run-on-start"`
+}
+
+// NotificationConfig opt-in notification kinds
+type NotificationConfig struct {
+	// Kinds "back_in_stock", "price_increase" and "delisted"
+	Kinds []string `mapstructure:"kinds"`
+	// PriceIncreaseThreshold percent the price must rise since the previous
+	// check for a price_increase notification, required with that kind
+	PriceIncreaseThreshold float64 `mapstructure:"price-increase-threshold"`
+}
@@ -1,8 +1,20 @@
 package contracts
 
-import "github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+import (
+	"context"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/google/uuid"
+)
 
 type Crawler interface {
-	SetupCrawlerEnv(crawlerPath string, productID string) error
-	RunCrawler(crawlerPath string, product entities.Product) (string, error)
+	SetupCrawlerEnv(ctx context.Context, site config.CrawlerSiteConfig, productID string) error
+	RunCrawler(ctx context.Context, site config.CrawlerSiteConfig, product entities.Product) (string, error)
+	RunCrawlerBatch(ctx context.Context, site config.CrawlerSiteConfig, products []entities.Product) (map[uuid.UUID]string, error)
+}
+
+type CrawlerRegistry interface {
+	Site(crawlerName string) (config.CrawlerSiteConfig, error)
+	CrawlerForLink(link string) (string, error)
 }
@@ -3,8 +3,13 @@
 package mocks
 
 import (
+	context "context"
+
+	config "github.com/JoaoLeal92/product-monitor-orchestrator/config"
 	entities "github.com/JoaoLeal92/product-monitor-orchestrator/entities"
 	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
 )
 
 // Crawler is an autogenerated mock type for the Crawler type
@@ -12,20 +17,43 @@ type Crawler struct {
 	mock.Mock
 }
 
-// RunCrawler provides a mock function with given fields: crawlerPath, product
-func (_m *Crawler) RunCrawler(crawlerPath string, product entities.Product) (string, error) {
-	ret := _m.Called(crawlerPath, product)
+// RunCrawler provides a mock function with given fields: ctx, site, product
+func (_m *Crawler) RunCrawler(ctx context.Context, site config.CrawlerSiteConfig, product entities.Product) (string, error) {
+	ret := _m.Called(ctx, site, product)
 
 	var r0 string
-	if rf, ok := ret.Get(0).(func(string, entities.Product) string); ok {
-		r0 = rf(crawlerPath, product)
+	if rf, ok := ret.Get(0).(func(context.Context, config.CrawlerSiteConfig, entities.Product) string); ok {
+		r0 = rf(ctx, site, product)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, entities.Product) error); ok {
-		r1 = rf(crawlerPath, product)
+	if rf, ok := ret.Get(1).(func(context.Context, config.CrawlerSiteConfig, entities.Product) error); ok {
+		r1 = rf(ctx, site, product)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunCrawlerBatch provides a mock function with given fields: ctx, site, products
+func (_m *Crawler) RunCrawlerBatch(ctx context.Context, site config.CrawlerSiteConfig, products []entities.Product) (map[uuid.UUID]string, error) {
+	ret := _m.Called(ctx, site, products)
+
+	var r0 map[uuid.UUID]string
+	if rf, ok := ret.Get(0).(func(context.Context, config.CrawlerSiteConfig, []entities.Product) map[uuid.UUID]string); ok {
+		r0 = rf(ctx, site, products)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uuid.UUID]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, config.CrawlerSiteConfig, []entities.Product) error); ok {
+		r1 = rf(ctx, site, products)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -33,13 +61,13 @@ func (_m *Crawler) RunCrawler(crawlerPath string, product entities.Product) (str
 	return r0, r1
 }
 
-// SetupCrawlerEnv provides a mock function with given fields: crawlerPath, productID
-func (_m *Crawler) SetupCrawlerEnv(crawlerPath string, productID string) error {
-	ret := _m.Called(crawlerPath, productID)
+// SetupCrawlerEnv provides a mock function with given fields: ctx, site, productID
+func (_m *Crawler) SetupCrawlerEnv(ctx context.Context, site config.CrawlerSiteConfig, productID string) error {
+	ret := _m.Called(ctx, site, productID)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(crawlerPath, productID)
+	if rf, ok := ret.Get(0).(func(context.Context, config.CrawlerSiteConfig, string) error); ok {
+		r0 = rf(ctx, site, productID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	entities "github.com/JoaoLeal92/product-monitor-orchestrator/entities"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,13 +14,13 @@ type ProductNotificationService struct {
 	mock.Mock
 }
 
-// Execute provides a mock function with given fields: product, productSearchResult
-func (_m *ProductNotificationService) Execute(product *entities.Product, productSearchResult *entities.ProductSearchResult) error {
-	ret := _m.Called(product, productSearchResult)
+// Execute provides a mock function with given fields: ctx, product, productSearchResult
+func (_m *ProductNotificationService) Execute(ctx context.Context, product *entities.Product, productSearchResult *entities.ProductSearchResult) error {
+	ret := _m.Called(ctx, product, productSearchResult)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*entities.Product, *entities.ProductSearchResult) error); ok {
-		r0 = rf(product, productSearchResult)
+	if rf, ok := ret.Get(0).(func(context.Context, *entities.Product, *entities.ProductSearchResult) error); ok {
+		r0 = rf(ctx, product, productSearchResult)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	entities "github.com/JoaoLeal92/product-monitor-orchestrator/entities"
 	mock "github.com/stretchr/testify/mock"
 
@@ -14,13 +16,13 @@ type ProductSearchHistoryRepository struct {
 	mock.Mock
 }
 
-// GetHistoryByProductID provides a mock function with given fields: productID
-func (_m *ProductSearchHistoryRepository) GetHistoryByProductID(productID uuid.UUID) ([]entities.ProductSearchResult, error) {
-	ret := _m.Called(productID)
+// GetHistoryByProductID provides a mock function with given fields: ctx, productID
+func (_m *ProductSearchHistoryRepository) GetHistoryByProductID(ctx context.Context, productID uuid.UUID) ([]entities.ProductSearchResult, error) {
+	ret := _m.Called(ctx, productID)
 
 	var r0 []entities.ProductSearchResult
-	if rf, ok := ret.Get(0).(func(uuid.UUID) []entities.ProductSearchResult); ok {
-		r0 = rf(productID)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []entities.ProductSearchResult); ok {
+		r0 = rf(ctx, productID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]entities.ProductSearchResult)
@@ -28,8 +30,8 @@ func (_m *ProductSearchHistoryRepository) GetHistoryByProductID(productID uuid.U
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
-		r1 = rf(productID)
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, productID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -37,13 +39,13 @@ func (_m *ProductSearchHistoryRepository) GetHistoryByProductID(productID uuid.U
 	return r0, r1
 }
 
-// InsertNewHistory provides a mock function with given fields: productSearch
-func (_m *ProductSearchHistoryRepository) InsertNewHistory(productSearch *entities.ProductSearchResult) error {
-	ret := _m.Called(productSearch)
+// InsertNewHistory provides a mock function with given fields: ctx, productSearch
+func (_m *ProductSearchHistoryRepository) InsertNewHistory(ctx context.Context, productSearch *entities.ProductSearchResult) error {
+	ret := _m.Called(ctx, productSearch)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*entities.ProductSearchResult) error); ok {
-		r0 = rf(productSearch)
+	if rf, ok := ret.Get(0).(func(context.Context, *entities.ProductSearchResult) error); ok {
+		r0 = rf(ctx, productSearch)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -3,8 +3,14 @@
 package mocks
 
 import (
+	context "context"
+
+	time "time"
+
 	entities "github.com/JoaoLeal92/product-monitor-orchestrator/entities"
 	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
 )
 
 // ProductsRepository is an autogenerated mock type for the ProductsRepository type
@@ -12,13 +18,13 @@ type ProductsRepository struct {
 	mock.Mock
 }
 
-// GetProductsListForCrawler provides a mock function with given fields:
-func (_m *ProductsRepository) GetProductsListForCrawler() ([]entities.Product, error) {
-	ret := _m.Called()
+// ClaimDueProducts provides a mock function with given fields: ctx, owner, batchSize, leaseDuration, defaultCheckInterval
+func (_m *ProductsRepository) ClaimDueProducts(ctx context.Context, owner string, batchSize int, leaseDuration time.Duration, defaultCheckInterval time.Duration) ([]entities.Product, error) {
+	ret := _m.Called(ctx, owner, batchSize, leaseDuration, defaultCheckInterval)
 
 	var r0 []entities.Product
-	if rf, ok := ret.Get(0).(func() []entities.Product); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, time.Duration, time.Duration) []entities.Product); ok {
+		r0 = rf(ctx, owner, batchSize, leaseDuration, defaultCheckInterval)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]entities.Product)
@@ -26,8 +32,8 @@ func (_m *ProductsRepository) GetProductsListForCrawler() ([]entities.Product, e
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, time.Duration, time.Duration) error); ok {
+		r1 = rf(ctx, owner, batchSize, leaseDuration, defaultCheckInterval)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -35,6 +41,94 @@ func (_m *ProductsRepository) GetProductsListForCrawler() ([]entities.Product, e
 	return r0, r1
 }
 
+// GetDueProductsForCrawler provides a mock function with given fields: ctx, defaultCheckInterval
+func (_m *ProductsRepository) GetDueProductsForCrawler(ctx context.Context, defaultCheckInterval time.Duration) ([]entities.Product, error) {
+	ret := _m.Called(ctx, defaultCheckInterval)
+
+	var r0 []entities.Product
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) []entities.Product); ok {
+		r0 = rf(ctx, defaultCheckInterval)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.Product)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Duration) error); ok {
+		r1 = rf(ctx, defaultCheckInterval)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProductsListForCrawler provides a mock function with given fields: ctx
+func (_m *ProductsRepository) GetProductsListForCrawler(ctx context.Context) ([]entities.Product, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []entities.Product
+	if rf, ok := ret.Get(0).(func(context.Context) []entities.Product); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.Product)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseLeases provides a mock function with given fields: ctx, owner, productIDs
+func (_m *ProductsRepository) ReleaseLeases(ctx context.Context, owner string, productIDs []uuid.UUID) error {
+	ret := _m.Called(ctx, owner, productIDs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []uuid.UUID) error); ok {
+		r0 = rf(ctx, owner, productIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RenewLeases provides a mock function with given fields: ctx, owner, productIDs, leaseDuration
+func (_m *ProductsRepository) RenewLeases(ctx context.Context, owner string, productIDs []uuid.UUID, leaseDuration time.Duration) error {
+	ret := _m.Called(ctx, owner, productIDs, leaseDuration)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []uuid.UUID, time.Duration) error); ok {
+		r0 = rf(ctx, owner, productIDs, leaseDuration)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateLastCheckedAt provides a mock function with given fields: ctx, productID, checkedAt
+func (_m *ProductsRepository) UpdateLastCheckedAt(ctx context.Context, productID uuid.UUID, checkedAt time.Time) error {
+	ret := _m.Called(ctx, productID, checkedAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, time.Time) error); ok {
+		r0 = rf(ctx, productID, checkedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type NewProductsRepositoryT interface {
 	mock.TestingT
 	Cleanup(func())
@@ -2,7 +2,11 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // QueueManager is an autogenerated mock type for the QueueManager type
 type QueueManager struct {
@@ -19,13 +23,13 @@ func (_m *QueueManager) CloseConnection() {
 	_m.Called()
 }
 
-// SendMessage provides a mock function with given fields: message
-func (_m *QueueManager) SendMessage(message interface{}) error {
-	ret := _m.Called(message)
+// SendMessage provides a mock function with given fields: ctx, message
+func (_m *QueueManager) SendMessage(ctx context.Context, message interface{}) error {
+	ret := _m.Called(ctx, message)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(interface{}) error); ok {
-		r0 = rf(message)
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}) error); ok {
+		r0 = rf(ctx, message)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1,7 +1,9 @@
 package contracts
 
+import "context"
+
 type QueueManager interface {
-	SendMessage(message interface{}) error
+	SendMessage(ctx context.Context, message interface{}) error
 	CloseConnection()
 	CloseChannel()
 }
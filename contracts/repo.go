@@ -1,6 +1,9 @@
 package contracts
 
 import (
+	"context"
+	"time"
+
 	"github.com/google/uuid"
 
 	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
@@ -12,10 +15,15 @@ type RepoManager interface {
 }
 
 type ProductsRepository interface {
-	GetProductsListForCrawler() ([]entities.Product, error)
+	GetProductsListForCrawler(ctx context.Context) ([]entities.Product, error)
+	GetDueProductsForCrawler(ctx context.Context, defaultCheckInterval time.Duration) ([]entities.Product, error)
+	ClaimDueProducts(ctx context.Context, owner string, batchSize int, leaseDuration time.Duration, defaultCheckInterval time.Duration) ([]entities.Product, error)
+	RenewLeases(ctx context.Context, owner string, productIDs []uuid.UUID, leaseDuration time.Duration) error
+	ReleaseLeases(ctx context.Context, owner string, productIDs []uuid.UUID) error
+	UpdateLastCheckedAt(ctx context.Context, productID uuid.UUID, checkedAt time.Time) error
 }
 
 type ProductSearchHistoryRepository interface {
-	InsertNewHistory(productSearch *entities.ProductSearchResult) error
-	GetHistoryByProductID(productID uuid.UUID) ([]entities.ProductSearchResult, error)
+	InsertNewHistory(ctx context.Context, productSearch *entities.ProductSearchResult) error
+	GetHistoryByProductID(ctx context.Context, productID uuid.UUID) ([]entities.ProductSearchResult, error)
 }
@@ -0,0 +1,11 @@
+package contracts
+
+import "github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+
+type ResultParser interface {
+	ParseCrawlerResult(crawlerOutput string) (*entities.CrawlerResult, error)
+}
+
+type ResultParserRegistry interface {
+	Parser(format string) (ResultParser, error)
+}
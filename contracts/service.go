@@ -1,13 +1,16 @@
 package contracts
 
 import (
+	"context"
+
 	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
 )
 
 type ProductNotificationService interface {
-	Execute(product *entities.Product, productSearchResult *entities.ProductSearchResult) error
+	Execute(ctx context.Context, product *entities.Product, productSearchResult *entities.ProductSearchResult) error
 }
 
 type CrawlerService interface {
-	Execute(products []entities.Product)
+	Execute(ctx context.Context, products []entities.Product) error
+	ExecuteWithLeases(ctx context.Context) error
 }
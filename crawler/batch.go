@@ -0,0 +1,109 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/crawler/runctx"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/google/uuid"
+)
+
+// RunCrawlerBatch crawls several products of the same crawler in a single run
+// and returns their outputs by product id. Products missing from the map got
+// no result from the crawler. The timeout of the crawler applies to each
+// product of the batch
+func (c *Crawler) RunCrawlerBatch(ctx context.Context, site config.CrawlerSiteConfig, products []entities.Product) (map[uuid.UUID]string, error) {
+	label := fmt.Sprintf("Lote de %d produtos", len(products))
+	c.logger.Info(fmt.Sprintf("%s Executando crawler %s", label, site.Name))
+
+	timeout := runctx.Timeout(c.cfg, site) * time.Duration(len(products))
+	runCtx, cancel := runctx.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if site.IsPersistent() {
+		outputs, err := c.workerPool(site).crawl(runCtx, products)
+		if stopErr := c.interruption(ctx, runCtx, label, timeout); stopErr != nil {
+			return outputs, stopErr
+		}
+
+		return outputs, err
+	}
+
+	var requests bytes.Buffer
+	if err := writeRequests(&requests, products); err != nil {
+		return nil, err
+	}
+
+	batchFile, err := writeBatchFile(requests.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(batchFile)
+
+	cmd, err := buildBatchCommand(site, batchFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var outb, errb bytes.Buffer
+	cmd.Stdin = bytes.NewReader(requests.Bytes())
+	cmd.Stdout = &outb
+	cmd.Stderr = &errb
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.waitOrKill(runCtx, cmd)
+	outputs := batchOutputs(outb.String(), products)
+	if stopErr := c.interruption(ctx, runCtx, label, timeout); stopErr != nil {
+		return outputs, stopErr
+	}
+	if err != nil {
+		return outputs, exitFailure(err, errb.String())
+	}
+
+	return outputs, nil
+}
+
+func writeBatchFile(requests []byte) (string, error) {
+	file, err := ioutil.TempFile("", "crawler-batch-*.jsonl")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	_, err = file.Write(requests)
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+// batchOutputs picks the output lines of the requested products
+func batchOutputs(out string, products []entities.Product) map[uuid.UUID]string {
+	requested := make(map[string]uuid.UUID, len(products))
+	for _, product := range products {
+		requested[product.ID.String()] = product.ID
+	}
+
+	outputs := make(map[uuid.UUID]string, len(products))
+	for _, line := range strings.Split(out, "\n") {
+		id, output, ok := parseWorkerLine(line)
+		if productID, isRequested := requested[id]; ok && isRequested {
+			outputs[productID] = output
+		}
+	}
+
+	return outputs
+}
@@ -0,0 +1,103 @@
+package crawler
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/template"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+)
+
+// commandTemplateData fields available to the args and env templates of a
+// crawler entry
+type commandTemplateData struct {
+	Link       string
+	ProductID  string
+	WorkingDir string
+	// BatchFile file listing the products of a batch run, one JSON line each
+	BatchFile string
+}
+
+// buildCommand renders the command of a crawler entry for the product
+func buildCommand(site config.CrawlerSiteConfig, product entities.Product) (*exec.Cmd, error) {
+	data := commandTemplateData{
+		Link:      product.Link,
+		ProductID: product.ID.String(),
+	}
+
+	return newCommand(site, site.Args, data)
+}
+
+// buildWorkerCommand renders the command starting a persistent worker of a
+// crawler entry. Only {{.WorkingDir}} is set, products are sent through stdin
+func buildWorkerCommand(site config.CrawlerSiteConfig) (*exec.Cmd, error) {
+	return newCommand(site, workerArgs(site), commandTemplateData{})
+}
+
+// buildBatchCommand renders the command crawling a batch of products. The
+// products are written to batchFile and to the process stdin
+func buildBatchCommand(site config.CrawlerSiteConfig, batchFile string) (*exec.Cmd, error) {
+	return newCommand(site, batchArgs(site), commandTemplateData{BatchFile: batchFile})
+}
+
+func batchArgs(site config.CrawlerSiteConfig) []string {
+	if len(site.BatchArgs) > 0 {
+		return site.BatchArgs
+	}
+
+	return workerArgs(site)
+}
+
+func workerArgs(site config.CrawlerSiteConfig) []string {
+	if len(site.WorkerArgs) > 0 {
+		return site.WorkerArgs
+	}
+
+	return site.Args
+}
+
+func newCommand(site config.CrawlerSiteConfig, argTemplates []string, data commandTemplateData) (*exec.Cmd, error) {
+	workingDir, err := filepath.Abs(site.WorkingDir)
+	if err != nil {
+		return nil, err
+	}
+	data.WorkingDir = workingDir
+
+	args, err := renderTemplates(argTemplates, data)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := renderTemplates(site.Env, data)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(site.Command, args...)
+	cmd.Dir = workingDir
+	cmd.Env = append(os.Environ(), env...)
+	setProcessGroup(cmd)
+
+	return cmd, nil
+}
+
+func renderTemplates(templates []string, data commandTemplateData) ([]string, error) {
+	rendered := make([]string, 0, len(templates))
+	for _, text := range templates {
+		tmpl, err := template.New("").Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, err
+		}
+
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, data); err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, out.String())
+	}
+
+	return rendered, nil
+}
@@ -2,65 +2,181 @@ package crawler
 
 import (
 	"bytes"
-	"errors"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"strings"
+	"sync"
+	"time"
 
 	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
 	"github.com/JoaoLeal92/product-monitor-orchestrator/contracts"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/crawler/runctx"
 	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
 )
 
+// killGracePeriod time given to a crawler to exit after SIGTERM before it is
+// killed
+const killGracePeriod = 5 * time.Second
+
 type Crawler struct {
 	cfg    *config.CrawlerConfig
 	logger contracts.LoggerContract
+
+	mu    sync.Mutex
+	pools map[string]*workerPool
 }
 
 func NewCrawler(cfg *config.CrawlerConfig, logger contracts.LoggerContract) *Crawler {
 	return &Crawler{
 		cfg:    cfg,
 		logger: logger,
+		pools:  make(map[string]*workerPool),
 	}
 }
 
-func (c *Crawler) SetupCrawlerEnv(crawlerPath string, productID string) error {
-	c.logger.Info(fmt.Sprintf("Preparando ambiente para processamento do produto %s", productID))
-	pipfile, err := filepath.Abs(
-		filepath.Join(
-			crawlerPath,
-			"Pipfile",
-		),
-	)
-	if err != nil {
+// SetupCrawlerEnv checks the crawler working directory is in place
+func (c *Crawler) SetupCrawlerEnv(ctx context.Context, site config.CrawlerSiteConfig, productID string) error {
+	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	os.Setenv("PIPENV_PIPFILE", pipfile)
+	c.logger.Info(fmt.Sprintf("Preparando ambiente para processamento do produto %s", productID))
+	if site.WorkingDir != "" {
+		info, err := os.Stat(site.WorkingDir)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("crawler %q working dir %q is not a directory", site.Name, site.WorkingDir)
+		}
+	}
+
 	fmt.Println("Ambiente pronto para execução")
 	return nil
 }
 
-func (c *Crawler) RunCrawler(crawlerPath string, product entities.Product) (string, error) {
-	c.logger.Info(fmt.Sprintf("%s Executando crawler no link %s", product.ID.String(), product.Link))
-	cmd := exec.Command("pipenv", "run", "python", crawlerPath, fmt.Sprintf("-u %s", product.Link))
+func (c *Crawler) RunCrawler(ctx context.Context, site config.CrawlerSiteConfig, product entities.Product) (string, error) {
+	c.logger.Info(fmt.Sprintf("%s Executando crawler %s no link %s", product.ID.String(), site.Name, product.Link))
+
+	timeout := runctx.Timeout(c.cfg, site)
+	runCtx, cancel := runctx.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if site.IsPersistent() {
+		outputs, err := c.workerPool(site).crawl(runCtx, []entities.Product{product})
+		if stopErr := c.interruption(ctx, runCtx, product.ID.String(), timeout); stopErr != nil {
+			return "", stopErr
+		}
+		if err != nil {
+			return "", err
+		}
+
+		return outputs[product.ID], nil
+	}
+
+	cmd, err := buildCommand(site, product)
+	if err != nil {
+		return "", err
+	}
 
 	var outb, errb bytes.Buffer
 	cmd.Stdout = &outb
 	cmd.Stderr = &errb
 
-	err := cmd.Run()
+	err = cmd.Start()
 	if err != nil {
 		return "", err
 	}
 
-	if !strings.Contains(errb.String(), "Loading .env environment variables") {
-		c.logger.Error(fmt.Sprintf("Erro no crawler do produto %s", product.Description))
-		c.logger.Error(errb.String())
-		return "", errors.New(errb.String())
+	err = c.waitOrKill(runCtx, cmd)
+	if stopErr := c.interruption(ctx, runCtx, product.ID.String(), timeout); stopErr != nil {
+		return "", stopErr
+	}
+	if err != nil {
+		return "", exitFailure(err, errb.String())
 	}
 
 	return outb.String(), nil
 }
+
+// Close stops the persistent workers, waiting up to ShutdownTimeout for the
+// ones busy with a request
+func (c *Crawler) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for name, pool := range c.pools {
+		wg.Add(1)
+		go func(pool *workerPool) {
+			defer wg.Done()
+			pool.close(c.cfg.ShutdownTimeout)
+		}(pool)
+		delete(c.pools, name)
+	}
+	wg.Wait()
+}
+
+func (c *Crawler) workerPool(site config.CrawlerSiteConfig) *workerPool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pool, ok := c.pools[site.Name]
+	if !ok {
+		workers := site.Workers
+		if workers == 0 {
+			workers = site.Concurrency
+		}
+		if workers == 0 {
+			workers = c.cfg.NumCrawlers
+		}
+
+		pool = newWorkerPool(site, workers, c.logger)
+		c.pools[site.Name] = pool
+	}
+
+	return pool
+}
+
+// interruption returns the error of a crawler run stopped because ctx was
+// cancelled or runCtx timed out, nil if neither happened
+func (c *Crawler) interruption(ctx context.Context, runCtx context.Context, label string, timeout time.Duration) error {
+	err := runctx.Err(ctx, runCtx, timeout)
+	switch {
+	case err == nil:
+	case ctx.Err() != nil:
+		c.logger.Warn(fmt.Sprintf("%s Crawler interrompido", label))
+	default:
+		c.logger.Warn(fmt.Sprintf("%s Crawler excedeu o tempo limite de %v", label, timeout))
+	}
+
+	return err
+}
+
+// waitOrKill waits for the command to exit. If ctx is done first, the whole
+// process group gets a SIGTERM and, after killGracePeriod, a SIGKILL
+func (c *Crawler) waitOrKill(ctx context.Context, cmd *exec.Cmd) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	terminateProcessGroup(cmd)
+	timer := time.NewTimer(killGracePeriod)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		killProcessGroup(cmd)
+		return <-done
+	}
+}
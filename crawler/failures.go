@@ -0,0 +1,44 @@
+package crawler
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+)
+
+// exitCodeKinds exit codes crawlers use to report why they failed, any other
+// non-zero code is an unknown failure
+var exitCodeKinds = map[int]entities.FailureKind{
+	10: entities.FailureBlocked,
+	11: entities.FailureNotFound,
+	12: entities.FailureLayoutChanged,
+	13: entities.FailureNetwork,
+	14: entities.FailureParse,
+}
+
+// exitFailure classifies the error of a crawler process by its exit code,
+// using the last line of stderr as the message
+func exitFailure(err error, stderr string) error {
+	kind := entities.FailureUnknown
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if exitKind, ok := exitCodeKinds[exitErr.ExitCode()]; ok {
+			kind = exitKind
+		}
+	}
+
+	return &entities.CrawlerError{
+		Kind:    kind,
+		Message: lastLine(stderr),
+		Err:     err,
+	}
+}
+
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+
+	return strings.TrimSpace(lines[len(lines)-1])
+}
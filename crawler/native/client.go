@@ -0,0 +1,163 @@
+package native
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+)
+
+const (
+	defaultUserAgent = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"
+	// maxBodySize product pages larger than this are cut, the product data is
+	// well within it
+	maxBodySize = 10 << 20
+)
+
+// defaultHeaders sent unless overridden in [crawlers.http.headers]
+var defaultHeaders = map[string]string{
+	"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+	"Accept-Language": "pt-BR,pt;q=0.9,en-US;q=0.8,en;q=0.7",
+	"Accept-Encoding": "gzip",
+}
+
+// Client HTTP client shared by the native crawlers. Cookies set by a website
+// are kept between requests, as a browser would. Failed requests are not
+// retried here but by the retry policy of the crawler service
+type Client struct {
+	http    *http.Client
+	headers http.Header
+}
+
+// Response body of a successful request, already decompressed
+type Response struct {
+	// URL final URL of the page, after redirects
+	URL        *url.URL
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// NewClient returns a client sending the headers of cfg, over the default
+// browser-like ones, with its own cookie jar
+func NewClient(cfg config.HTTPConfig) *Client {
+	// cookiejar.New only fails with a PublicSuffixList, which is not used
+	jar, _ := cookiejar.New(nil)
+
+	headers := make(http.Header)
+	for key, value := range defaultHeaders {
+		headers.Set(key, value)
+	}
+	headers.Set("User-Agent", defaultUserAgent)
+	if cfg.UserAgent != "" {
+		headers.Set("User-Agent", cfg.UserAgent)
+	}
+	for key, value := range cfg.Headers {
+		headers.Set(key, value)
+	}
+
+	return &Client{
+		http:    &http.Client{Jar: jar},
+		headers: headers,
+	}
+}
+
+// Get requests the page. Responses other than 2xx are returned as a
+// CrawlerError of the matching kind, with the Retry-After of the website
+func (c *Client) Get(ctx context.Context, link string) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &entities.CrawlerError{Kind: entities.FailureNetwork, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if err := statusFailure(resp); err != nil {
+		return nil, err
+	}
+
+	body, err := readBody(resp)
+	if err != nil {
+		return nil, &entities.CrawlerError{Kind: entities.FailureNetwork, Message: "could not read the response body", Err: err}
+	}
+
+	return &Response{
+		URL:        resp.Request.URL,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}, nil
+}
+
+// readBody reads the body, decompressing it when the website honoured the
+// Accept-Encoding header
+func readBody(resp *http.Response) ([]byte, error) {
+	var body io.Reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	return ioutil.ReadAll(io.LimitReader(body, maxBodySize))
+}
+
+// statusFailure classifies responses other than 2xx
+func statusFailure(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	kind := entities.FailureUnknown
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		kind = entities.FailureNotFound
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		kind = entities.FailureBlocked
+	case resp.StatusCode >= 500:
+		kind = entities.FailureNetwork
+	}
+
+	return &entities.CrawlerError{
+		Kind:       kind,
+		Message:    fmt.Sprintf("%s returned %s", resp.Request.URL, resp.Status),
+		RetryAfter: retryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// retryAfter reads a Retry-After header, given in seconds or as an HTTP date
+func retryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
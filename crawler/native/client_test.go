@@ -0,0 +1,123 @@
+package native
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/session":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+			http.Redirect(w, r, "/product", http.StatusFound)
+		case "/product":
+			assert.Equal(t, "product-monitor", r.Header.Get("User-Agent"))
+			assert.Equal(t, "pt-BR", r.Header.Get("Accept-Language"))
+			cookie, err := r.Cookie("session")
+			require.NoError(t, err)
+			assert.Equal(t, "abc", cookie.Value)
+
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			gz.Write([]byte("<html>product</html>"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(config.HTTPConfig{
+		UserAgent: "product-monitor",
+		Headers:   map[string]string{"accept-language": "pt-BR"},
+	})
+	resp, err := client.Get(context.Background(), server.URL+"/session")
+
+	require.NoError(t, err)
+	assert.Equal(t, "<html>product</html>", string(resp.Body))
+	assert.Equal(t, "/product", resp.URL.Path)
+}
+
+func TestClientGetFailures(t *testing.T) {
+	tests := map[string]struct {
+		status           int
+		expectedKind     entities.FailureKind
+		expectedRequests int
+	}{
+		"not-found":         {http.StatusNotFound, entities.FailureNotFound, 1},
+		"forbidden":         {http.StatusForbidden, entities.FailureBlocked, 1},
+		"too-many-requests": {http.StatusTooManyRequests, entities.FailureBlocked, 1},
+		"server-error":      {http.StatusBadGateway, entities.FailureNetwork, 1},
+		"bad-request":       {http.StatusBadRequest, entities.FailureUnknown, 1},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(testData.status)
+			}))
+			defer server.Close()
+
+			client := NewClient(config.HTTPConfig{})
+			_, err := client.Get(context.Background(), server.URL)
+
+			assert.Equal(t, testData.expectedKind, entities.FailureKindOf(err))
+			assert.Equal(t, testData.expectedRequests, requests)
+		})
+	}
+}
+
+func TestClientGetUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	client := NewClient(config.HTTPConfig{})
+	_, err := client.Get(context.Background(), server.URL)
+
+	assert.ErrorIs(t, err, entities.ErrCrawlerNetwork)
+}
+
+func TestClientGetRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(config.HTTPConfig{})
+	_, err := client.Get(context.Background(), server.URL)
+
+	assert.ErrorIs(t, err, entities.ErrCrawlerBlocked)
+	assert.Equal(t, 2*time.Minute, entities.RetryAfterOf(err))
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		value    string
+		expected time.Duration
+	}{
+		"seconds":     {"30", 30 * time.Second},
+		"date":        {"Wed, 01 Jun 2022 12:01:00 GMT", time.Minute},
+		"past-date":   {"Wed, 01 Jun 2022 11:00:00 GMT", 0},
+		"missing":     {"", 0},
+		"not-a-value": {"soon", 0},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expected, retryAfter(testData.value, now))
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/contracts"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/crawler/runctx"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	crawlerparser "github.com/JoaoLeal92/product-monitor-orchestrator/infra/crawlerParser"
+	"github.com/google/uuid"
+)
+
+// Store crawler of a website written in Go. Failures are returned as a
+// CrawlerError, e.g. from LayoutChanged; the ones of the Client already are
+type Store interface {
+	Crawl(ctx context.Context, client *Client, product entities.Product) (crawlerparser.ProtocolResult, error)
+}
+
+// StoreFunc lets a function be used as a Store
+type StoreFunc func(ctx context.Context, client *Client, product entities.Product) (crawlerparser.ProtocolResult, error)
+
+func (f StoreFunc) Crawl(ctx context.Context, client *Client, product entities.Product) (crawlerparser.ProtocolResult, error) {
+	return f(ctx, client, product)
+}
+
+// Crawler runs the stores registered for the crawlers of type "native". Their
+// results are returned as protocol lines, the same output an exec crawler
+// prints, so both are parsed alike
+type Crawler struct {
+	cfg    *config.CrawlerConfig
+	logger contracts.LoggerContract
+	client *Client
+
+	mu     sync.RWMutex
+	stores map[string]Store
+	// selectors stores of the "selector" entries, by entry name
+	selectors map[string]*selectorStore
+}
+
+// NewCrawler builds the stores of the "selector" entries of cfg once. Entries
+// whose selectors are invalid are left out and reported by CheckSites
+func NewCrawler(cfg *config.CrawlerConfig, logger contracts.LoggerContract) *Crawler {
+	selectors := make(map[string]*selectorStore)
+	for _, site := range cfg.Sites {
+		if site.Type != config.CrawlerTypeSelector {
+			continue
+		}
+		if store, err := newSelectorStore(site.Selectors); err == nil {
+			selectors[site.Name] = store
+		}
+	}
+
+	return &Crawler{
+		cfg:       cfg,
+		logger:    logger,
+		client:    NewClient(cfg.HTTP),
+		stores:    map[string]Store{GenericStore: Generic},
+		selectors: selectors,
+	}
+}
+
+// Register makes the store run the products of the crawler entries with the
+// given name as their store. The Generic store is registered by default
+func (c *Crawler) Register(name string, store Store) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stores[name] = store
+}
+
+// CheckSites returns an error naming the native crawler entries that have no
+// store registered and the selector entries whose store could not be built
+func (c *Crawler) CheckSites(sites []config.CrawlerSiteConfig) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	missing := []string{}
+	for _, site := range sites {
+		if _, ok := c.stores[site.StoreName()]; site.Type == config.CrawlerTypeNative && !ok {
+			missing = append(missing, site.StoreName())
+		}
+		if _, ok := c.selectors[site.Name]; site.Type == config.CrawlerTypeSelector && !ok {
+			missing = append(missing, site.Name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("%w: no native crawler registered for %s", entities.ErrUnknownCrawler, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// SetupCrawlerEnv native crawlers need no setup, it only checks a store is
+// registered for the crawler
+func (c *Crawler) SetupCrawlerEnv(ctx context.Context, site config.CrawlerSiteConfig, productID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, err := c.store(site)
+	return err
+}
+
+func (c *Crawler) RunCrawler(ctx context.Context, site config.CrawlerSiteConfig, product entities.Product) (string, error) {
+	c.logger.Info(fmt.Sprintf("%s Executando crawler nativo %s no link %s", product.ID.String(), site.Name, product.Link))
+
+	store, err := c.store(site)
+	if err != nil {
+		return "", err
+	}
+
+	timeout := runctx.Timeout(c.cfg, site)
+	runCtx, cancel := runctx.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err := store.Crawl(runCtx, c.client, product)
+	if stopErr := runctx.Err(ctx, runCtx, timeout); stopErr != nil {
+		return "", stopErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return protocolLine(result)
+}
+
+// RunCrawlerBatch crawls the products one after the other. Failures of a
+// product are returned as protocol error lines, as a batch crawler would print
+// them, so they are classified and retried alike. A cancelled ctx stops the
+// batch and is returned as is
+func (c *Crawler) RunCrawlerBatch(ctx context.Context, site config.CrawlerSiteConfig, products []entities.Product) (map[uuid.UUID]string, error) {
+	outputs := make(map[uuid.UUID]string, len(products))
+	for _, product := range products {
+		if err := ctx.Err(); err != nil {
+			return outputs, err
+		}
+
+		output, err := c.RunCrawler(ctx, site, product)
+		if ctx.Err() != nil {
+			return outputs, ctx.Err()
+		}
+		if err != nil {
+			output, err = protocolLine(crawlerparser.ProtocolResult{
+				Error: &crawlerparser.ProtocolError{
+					Code:    string(entities.FailureKindOf(err)),
+					Message: err.Error(),
+				},
+			})
+			if err != nil {
+				return outputs, err
+			}
+		}
+		outputs[product.ID] = output
+	}
+
+	return outputs, nil
+}
+
+func (c *Crawler) store(site config.CrawlerSiteConfig) (Store, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if site.Type == config.CrawlerTypeSelector {
+		store, ok := c.selectors[site.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w: no selector crawler built for %q", entities.ErrUnknownCrawler, site.Name)
+		}
+		return store, nil
+	}
+
+	store, ok := c.stores[site.StoreName()]
+	if !ok {
+		return nil, fmt.Errorf("%w: no native crawler registered for %q", entities.ErrUnknownCrawler, site.StoreName())
+	}
+
+	return store, nil
+}
+
+func protocolLine(result crawlerparser.ProtocolResult) (string, error) {
+	result.SchemaVersion = crawlerparser.ProtocolSchemaVersion
+
+	line, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+
+	return string(line), nil
+}
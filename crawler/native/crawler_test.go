@@ -0,0 +1,168 @@
+package native
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	mocks "github.com/JoaoLeal92/product-monitor-orchestrator/contracts/mocks"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	crawlerparser "github.com/JoaoLeal92/product-monitor-orchestrator/infra/crawlerParser"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureStore reads the product page of testdata/product.html
+var fixtureStore = StoreFunc(func(ctx context.Context, client *Client, product entities.Product) (crawlerparser.ProtocolResult, error) {
+	page, err := client.GetPage(ctx, product.Link)
+	if err != nil {
+		return crawlerparser.ProtocolResult{}, err
+	}
+
+	price, err := strconv.Atoi(page.Attr(".price", "data-cents"))
+	if err != nil {
+		return crawlerparser.ProtocolResult{}, LayoutChanged("price not found in %s", product.Link)
+	}
+
+	return crawlerparser.ProtocolResult{
+		Price:    &price,
+		Discount: page.Text(".discount"),
+		Link:     page.Meta("og:url"),
+	}, nil
+})
+
+func newTestCrawler(t *testing.T, cfg *config.CrawlerConfig) *Crawler {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockLogger.On("Info", mock.Anything).Return(nil).Maybe()
+
+	crawler := NewCrawler(cfg, mockLogger)
+	crawler.Register("loja-exemplo", fixtureStore)
+
+	return crawler
+}
+
+func TestCrawlerRunCrawler(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+
+	crawler := newTestCrawler(t, &config.CrawlerConfig{})
+	site := config.CrawlerSiteConfig{Name: "loja-exemplo", Type: config.CrawlerTypeNative}
+	product := entities.Product{ID: uuid.New(), Link: server.URL + "/product.html"}
+
+	require.NoError(t, crawler.SetupCrawlerEnv(context.Background(), site, product.ID.String()))
+	output, err := crawler.RunCrawler(context.Background(), site, product)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"schema_version": 1, "price": 199990, "original_price": null, "discount": "20%", "link": "https://www.loja-exemplo.com.br/produto/4060"}`, output)
+}
+
+func TestCrawlerRunCrawlerFailures(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+
+	crawler := newTestCrawler(t, &config.CrawlerConfig{})
+	site := config.CrawlerSiteConfig{Name: "loja-exemplo", Type: config.CrawlerTypeNative}
+
+	_, err := crawler.RunCrawler(context.Background(), site, entities.Product{Link: server.URL + "/missing.html"})
+	assert.ErrorIs(t, err, entities.ErrProductNotFound)
+
+	_, err = crawler.RunCrawler(context.Background(), site, entities.Product{Link: server.URL + "/"})
+	assert.ErrorIs(t, err, entities.ErrLayoutChanged)
+
+	_, err = crawler.RunCrawler(context.Background(), config.CrawlerSiteConfig{Name: "other-store", Type: config.CrawlerTypeNative}, entities.Product{})
+	assert.ErrorIs(t, err, entities.ErrUnknownCrawler)
+}
+
+func TestCrawlerRunCrawlerTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	crawler := newTestCrawler(t, &config.CrawlerConfig{Timeout: 50 * time.Millisecond})
+	site := config.CrawlerSiteConfig{Name: "loja-exemplo", Type: config.CrawlerTypeNative}
+
+	_, err := crawler.RunCrawler(context.Background(), site, entities.Product{Link: server.URL})
+
+	assert.Equal(t, entities.FailureTimeout, entities.FailureKindOf(err))
+}
+
+func TestCrawlerRunCrawlerBatch(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+
+	crawler := newTestCrawler(t, &config.CrawlerConfig{})
+	site := config.CrawlerSiteConfig{Name: "loja-exemplo", Type: config.CrawlerTypeNative, BatchSize: 2}
+	products := []entities.Product{
+		{ID: uuid.New(), Link: server.URL + "/product.html"},
+		{ID: uuid.New(), Link: server.URL + "/missing.html"},
+	}
+
+	outputs, err := crawler.RunCrawlerBatch(context.Background(), site, products)
+	require.NoError(t, err)
+
+	parser := crawlerparser.NewResultParser()
+	result, err := parser.ParseCrawlerResult(outputs[products[0].ID])
+	require.NoError(t, err)
+	assert.Equal(t, 199990, result.Price)
+
+	_, err = parser.ParseCrawlerResult(outputs[products[1].ID])
+	assert.ErrorIs(t, err, entities.ErrProductNotFound)
+}
+
+func TestCrawlerRunCrawlerBatchCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	crawler := newTestCrawler(t, &config.CrawlerConfig{})
+	crawler.Register("loja-lenta", StoreFunc(func(ctx context.Context, client *Client, product entities.Product) (crawlerparser.ProtocolResult, error) {
+		cancel()
+		return crawlerparser.ProtocolResult{}, ctx.Err()
+	}))
+	site := config.CrawlerSiteConfig{Name: "loja-lenta", Type: config.CrawlerTypeNative, BatchSize: 2}
+
+	outputs, err := crawler.RunCrawlerBatch(ctx, site, []entities.Product{{ID: uuid.New()}, {ID: uuid.New()}})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, outputs)
+}
+
+func TestCrawlerCheckSites(t *testing.T) {
+	crawler := newTestCrawler(t, &config.CrawlerConfig{})
+
+	err := crawler.CheckSites([]config.CrawlerSiteConfig{
+		{Name: "loja-exemplo", Type: config.CrawlerTypeNative},
+		{Name: "amazon", Command: "pipenv"},
+	})
+	require.NoError(t, err)
+
+	err = crawler.CheckSites([]config.CrawlerSiteConfig{{Name: "kabum", Type: config.CrawlerTypeNative}})
+	assert.ErrorIs(t, err, entities.ErrUnknownCrawler)
+}
+
+func TestCrawlerSelectorSites(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+
+	sites := []config.CrawlerSiteConfig{
+		{Name: "loja-sem-dados", Type: config.CrawlerTypeSelector, Selectors: config.SelectorConfig{Price: config.FieldSelector{CSS: ".final-price"}}},
+		{Name: "loja-invalida", Type: config.CrawlerTypeSelector, Selectors: config.SelectorConfig{URLPattern: "(", Price: config.FieldSelector{CSS: ".final-price"}}},
+	}
+	crawler := newTestCrawler(t, &config.CrawlerConfig{Sites: sites})
+
+	output, err := crawler.RunCrawler(context.Background(), sites[0], entities.Product{ID: uuid.New(), Link: server.URL + "/selector.html"})
+	require.NoError(t, err)
+	assert.Contains(t, output, `"price":134990`)
+
+	_, err = crawler.RunCrawler(context.Background(), sites[1], entities.Product{ID: uuid.New(), Link: server.URL + "/selector.html"})
+	assert.ErrorIs(t, err, entities.ErrUnknownCrawler)
+	assert.ErrorIs(t, crawler.CheckSites(sites), entities.ErrUnknownCrawler)
+}
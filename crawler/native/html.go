@@ -0,0 +1,81 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Page HTML document of a product page
+type Page struct {
+	*goquery.Document
+	Response *Response
+}
+
+// GetPage requests the page and parses it as HTML
+func (c *Client) GetPage(ctx context.Context, link string) (*Page, error) {
+	resp, err := c.Get(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(resp.Body))
+	if err != nil {
+		return nil, &entities.CrawlerError{Kind: entities.FailureParse, Message: "invalid HTML", Err: err}
+	}
+	doc.Url = resp.URL
+
+	return &Page{Document: doc, Response: resp}, nil
+}
+
+// Text returns the trimmed text of the first selector that matches a
+// non-empty element, or "" when none does
+func (p *Page) Text(selectors ...string) string {
+	for _, selector := range selectors {
+		text := ""
+		p.Find(selector).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+			text = strings.Join(strings.Fields(s.Text()), " ")
+			return text == ""
+		})
+		if text != "" {
+			return text
+		}
+	}
+
+	return ""
+}
+
+// Attr returns the trimmed value of the attribute in the first element
+// matching the selector that has it
+func (p *Page) Attr(selector string, attr string) string {
+	value := ""
+	p.Find(selector).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		value = strings.TrimSpace(s.AttrOr(attr, ""))
+		return value == ""
+	})
+
+	return value
+}
+
+// Meta returns the content of the <meta> tag with the given property or name,
+// as used by OpenGraph ("og:price:amount") and the description tags
+func (p *Page) Meta(name string) string {
+	if content := p.Attr(fmt.Sprintf("meta[property=%q]", name), "content"); content != "" {
+		return content
+	}
+
+	return p.Attr(fmt.Sprintf("meta[name=%q]", name), "content")
+}
+
+// LayoutChanged failure of a crawler that loaded the page but could not find
+// the product data in it
+func LayoutChanged(format string, args ...interface{}) error {
+	return &entities.CrawlerError{
+		Kind:    entities.FailureLayoutChanged,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
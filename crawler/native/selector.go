@@ -0,0 +1,246 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	crawlerparser "github.com/JoaoLeal92/product-monitor-orchestrator/infra/crawlerParser"
+)
+
+// selectorStore crawler of type "selector", reading the product page with the
+// selectors of its entry instead of code
+type selectorStore struct {
+	cfg        config.SelectorConfig
+	urlPattern *regexp.Regexp
+}
+
+// fieldValue value a selector found. Number is set for JSON numbers, which
+// are written the same whatever the price locale of the store
+type fieldValue struct {
+	Text   string
+	Number bool
+	Bool   *bool
+}
+
+func newSelectorStore(cfg config.SelectorConfig) (*selectorStore, error) {
+	store := &selectorStore{cfg: cfg}
+	if cfg.URLPattern != "" {
+		urlPattern, err := regexp.Compile(cfg.URLPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid url-pattern: %w", err)
+		}
+		store.urlPattern = urlPattern
+	}
+
+	return store, nil
+}
+
+func (s *selectorStore) Crawl(ctx context.Context, client *Client, product entities.Product) (crawlerparser.ProtocolResult, error) {
+	if s.urlPattern != nil && !s.urlPattern.MatchString(product.Link) {
+		return crawlerparser.ProtocolResult{}, fmt.Errorf("%w: %s does not match the url-pattern", entities.ErrLinkNotOfCrawler, product.Link)
+	}
+
+	read, link, err := s.fetch(ctx, client, product)
+	if err != nil {
+		return crawlerparser.ProtocolResult{}, err
+	}
+
+	value, ok := read(s.cfg.Price)
+	if !ok {
+		return crawlerparser.ProtocolResult{}, LayoutChanged("price not found in %s", product.Link)
+	}
+	price, err := s.parsePrice(value)
+	if err != nil {
+		return crawlerparser.ProtocolResult{}, err
+	}
+
+	result := crawlerparser.ProtocolResult{
+		Price:    &price,
+		Currency: s.cfg.Currency,
+		Link:     link,
+	}
+	if value, ok := read(s.cfg.OriginalPrice); ok {
+		originalPrice, err := s.parsePrice(value)
+		if err != nil {
+			return crawlerparser.ProtocolResult{}, err
+		}
+		result.OriginalPrice = &originalPrice
+	}
+	if value, ok := read(s.cfg.Discount); ok {
+		result.Discount = value.Text
+	}
+	if value, ok := read(s.cfg.Stock); ok {
+		result.Availability = s.availability(value)
+	}
+
+	return result, nil
+}
+
+// fetch requests the product page and returns how its fields are read, along
+// with the link the page was served from
+func (s *selectorStore) fetch(ctx context.Context, client *Client, product entities.Product) (func(config.FieldSelector) (fieldValue, bool), string, error) {
+	if s.cfg.Format == config.SelectorFormatJSON {
+		resp, err := client.Get(ctx, product.Link)
+		if err != nil {
+			return nil, "", err
+		}
+
+		data, err := decodeJSON(resp.Body)
+		if err != nil {
+			return nil, "", &entities.CrawlerError{Kind: entities.FailureParse, Message: "invalid JSON page", Err: err}
+		}
+
+		read := func(field config.FieldSelector) (fieldValue, bool) {
+			if !field.IsSet() {
+				return fieldValue{}, false
+			}
+			return jsonPath(data, field.JSONPath)
+		}
+		return read, resp.URL.String(), nil
+	}
+
+	page, err := client.GetPage(ctx, product.Link)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return page.field, page.Response.URL.String(), nil
+}
+
+// field reads the value of the first element matching the CSS selector,
+// looking the JSON path up in its text when there is one
+func (p *Page) field(field config.FieldSelector) (fieldValue, bool) {
+	if !field.IsSet() {
+		return fieldValue{}, false
+	}
+
+	selection := p.Find(field.CSS).First()
+	if selection.Length() == 0 {
+		return fieldValue{}, false
+	}
+
+	text := selection.Text()
+	if field.Attr != "" {
+		attr, ok := selection.Attr(field.Attr)
+		if !ok {
+			return fieldValue{}, false
+		}
+		text = attr
+	}
+	if field.JSONPath == "" {
+		text = strings.Join(strings.Fields(text), " ")
+		return fieldValue{Text: text}, text != ""
+	}
+
+	data, err := decodeJSON([]byte(text))
+	if err != nil {
+		return fieldValue{}, false
+	}
+
+	return jsonPath(data, field.JSONPath)
+}
+
+func decodeJSON(body []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var data interface{}
+	err := decoder.Decode(&data)
+
+	return data, err
+}
+
+// jsonPath looks up a dot separated path, where numbers index lists and
+// "offers[0]" is read as "offers.0"
+func jsonPath(data interface{}, path string) (fieldValue, bool) {
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	for _, key := range strings.Split(path, ".") {
+		if key == "" {
+			continue
+		}
+
+		switch node := data.(type) {
+		case map[string]interface{}:
+			data = node[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return fieldValue{}, false
+			}
+			data = node[i]
+		default:
+			return fieldValue{}, false
+		}
+	}
+
+	switch value := data.(type) {
+	case string:
+		value = strings.TrimSpace(value)
+		return fieldValue{Text: value}, value != ""
+	case json.Number:
+		return fieldValue{Text: value.String(), Number: true}, true
+	case bool:
+		return fieldValue{Text: strconv.FormatBool(value), Bool: &value}, true
+	default:
+		return fieldValue{}, false
+	}
+}
+
+// parsePrice reads a price into cents. Texts follow the price locale of the
+// store, JSON numbers are plain decimals
+func (s *selectorStore) parsePrice(value fieldValue) (int, error) {
+	var price int
+	var err error
+	switch {
+	case s.cfg.PriceInCents:
+		price, err = strconv.Atoi(strings.TrimFunc(value.Text, func(r rune) bool { return !unicode.IsDigit(r) }))
+	case value.Number:
+		var ok bool
+		if price, ok = parseSchemaPrice(value.Text); !ok {
+			err = fmt.Errorf("invalid number %s", value.Text)
+		}
+	default:
+		locale := s.cfg.PriceLocale
+		if locale == "" {
+			locale = entities.LocalePtBR
+		}
+		price, err = entities.ParsePrice(value.Text, locale)
+	}
+	if err != nil {
+		return 0, &entities.CrawlerError{Kind: entities.FailureParse, Message: fmt.Sprintf("invalid price %q", value.Text), Err: err}
+	}
+
+	return price, nil
+}
+
+// availability reads the stock field: JSON booleans tell whether the product
+// is in stock, texts are matched against the schema.org values and
+// OutOfStockText
+func (s *selectorStore) availability(value fieldValue) string {
+	if value.Bool != nil {
+		if *value.Bool {
+			return "in_stock"
+		}
+		return "out_of_stock"
+	}
+	if availability := parseAvailability(value.Text); availability != "" {
+		return availability
+	}
+
+	text := strings.ToLower(value.Text)
+	for _, outOfStock := range s.cfg.OutOfStockText {
+		if strings.Contains(text, strings.ToLower(outOfStock)) {
+			return "out_of_stock"
+		}
+	}
+
+	return "in_stock"
+}
@@ -0,0 +1,94 @@
+package native
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	crawlerparser "github.com/JoaoLeal92/product-monitor-orchestrator/infra/crawlerParser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectorStore(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+
+	tests := map[string]struct {
+		page           string
+		selectors      config.SelectorConfig
+		expectedResult crawlerparser.ProtocolResult
+	}{
+		"html": {
+			"/selector.html",
+			config.SelectorConfig{
+				URLPattern:     `/selector\.html$`,
+				Currency:       "BRL",
+				Price:          config.FieldSelector{CSS: ".prices .final-price"},
+				OriginalPrice:  config.FieldSelector{CSS: "script#__NEXT_DATA__", JSONPath: "props.pageProps.product.listPrice"},
+				Discount:       config.FieldSelector{CSS: ".discount-tag"},
+				Stock:          config.FieldSelector{CSS: ".availability"},
+				OutOfStockText: []string{"esgotado", "indisponível"},
+			},
+			crawlerparser.ProtocolResult{
+				Price:         intPtr(134990),
+				OriginalPrice: intPtr(149990),
+				Discount:      "10% OFF",
+				Currency:      "BRL",
+				Availability:  "in_stock",
+				Link:          server.URL + "/selector.html",
+			},
+		},
+		"json": {
+			"/selector.json",
+			config.SelectorConfig{
+				Format:        config.SelectorFormatJSON,
+				PriceInCents:  true,
+				Price:         config.FieldSelector{JSONPath: "product.offers[0].price"},
+				OriginalPrice: config.FieldSelector{JSONPath: "product.offers.0.list_price"},
+				Stock:         config.FieldSelector{JSONPath: "product.offers.0.in_stock"},
+			},
+			crawlerparser.ProtocolResult{
+				Price:         intPtr(134990),
+				OriginalPrice: intPtr(149990),
+				Availability:  "out_of_stock",
+				Link:          server.URL + "/selector.json",
+			},
+		},
+	}
+
+	client := NewClient(config.HTTPConfig{})
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			store, err := newSelectorStore(testData.selectors)
+			require.NoError(t, err)
+
+			result, err := store.Crawl(context.Background(), client, entities.Product{Link: server.URL + testData.page})
+
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedResult, result)
+		})
+	}
+}
+
+func TestSelectorStoreFailures(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+	client := NewClient(config.HTTPConfig{})
+	product := entities.Product{Link: server.URL + "/selector.html"}
+
+	store, _ := newSelectorStore(config.SelectorConfig{Price: config.FieldSelector{CSS: ".price-that-moved"}})
+	_, err := store.Crawl(context.Background(), client, product)
+	assert.ErrorIs(t, err, entities.ErrLayoutChanged)
+
+	store, _ = newSelectorStore(config.SelectorConfig{Price: config.FieldSelector{CSS: ".availability"}})
+	_, err = store.Crawl(context.Background(), client, product)
+	assert.ErrorIs(t, err, entities.ErrCrawlerParse)
+
+	store, _ = newSelectorStore(config.SelectorConfig{URLPattern: `^https://www\.other-store\.com/`, Price: config.FieldSelector{CSS: ".final-price"}})
+	_, err = store.Crawl(context.Background(), client, product)
+	assert.ErrorIs(t, err, entities.ErrLinkNotOfCrawler)
+}
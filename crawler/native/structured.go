@@ -0,0 +1,353 @@
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	crawlerparser "github.com/JoaoLeal92/product-monitor-orchestrator/infra/crawlerParser"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// GenericStore name the Generic store is registered under
+const GenericStore = "generic"
+
+// Generic reads the product data stores publish for search engines, so any
+// store that does can be monitored without a crawler of its own
+var Generic = StoreFunc(func(ctx context.Context, client *Client, product entities.Product) (crawlerparser.ProtocolResult, error) {
+	page, err := client.GetPage(ctx, product.Link)
+	if err != nil {
+		return crawlerparser.ProtocolResult{}, err
+	}
+
+	data := ExtractProduct(page)
+	if data.Price == nil && data.Availability == "" {
+		return crawlerparser.ProtocolResult{}, LayoutChanged("no schema.org product data in %s", product.Link)
+	}
+
+	return data, nil
+})
+
+// schemaAvailability protocol availability of the schema.org ItemAvailability
+// values and of the short forms used by OpenGraph
+var schemaAvailability = map[string]string{
+	"instock":             "in_stock",
+	"in stock":            "in_stock",
+	"instoreonly":         "in_stock",
+	"onlineonly":          "in_stock",
+	"outofstock":          "out_of_stock",
+	"out of stock":        "out_of_stock",
+	"oos":                 "out_of_stock",
+	"soldout":             "out_of_stock",
+	"preorder":            "preorder",
+	"presale":             "preorder",
+	"backorder":           "preorder",
+	"discontinued":        "unavailable",
+	"limitedavailability": "limited",
+}
+
+// ExtractProduct reads the product data declared in the page as JSON-LD,
+// microdata and OpenGraph tags, in that order of precedence. Each field is
+// taken from the first source that has it
+func ExtractProduct(page *Page) crawlerparser.ProtocolResult {
+	result := crawlerparser.ProtocolResult{}
+	mergeResult(&result, jsonLDProduct(page))
+	mergeResult(&result, microdataProduct(page))
+	mergeResult(&result, openGraphProduct(page))
+
+	if link := page.Attr(`link[rel="canonical"]`, "href"); result.Link == "" && link != "" {
+		result.Link = link
+	}
+	result.Link = absoluteLink(page, result.Link)
+
+	return result
+}
+
+func mergeResult(result *crawlerparser.ProtocolResult, source crawlerparser.ProtocolResult) {
+	if result.Price == nil {
+		result.Price = source.Price
+	}
+	if result.OriginalPrice == nil {
+		result.OriginalPrice = source.OriginalPrice
+	}
+	if result.Currency == "" {
+		result.Currency = source.Currency
+	}
+	if result.Availability == "" {
+		result.Availability = source.Availability
+	}
+	if result.Seller == "" {
+		result.Seller = source.Seller
+	}
+	if result.Link == "" {
+		result.Link = source.Link
+	}
+}
+
+// jsonLDProduct reads the first Product of the ld+json scripts, which may hold
+// a single node, a list of them or a @graph
+func jsonLDProduct(page *Page) crawlerparser.ProtocolResult {
+	result := crawlerparser.ProtocolResult{}
+	page.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		decoder := json.NewDecoder(strings.NewReader(s.Text()))
+		decoder.UseNumber()
+
+		var data interface{}
+		if err := decoder.Decode(&data); err != nil {
+			return true
+		}
+
+		product, ok := findJSONLDProduct(data)
+		if !ok {
+			return true
+		}
+		result = jsonLDResult(product)
+
+		return false
+	})
+
+	return result
+}
+
+func findJSONLDProduct(data interface{}) (map[string]interface{}, bool) {
+	switch node := data.(type) {
+	case []interface{}:
+		for _, item := range node {
+			if product, ok := findJSONLDProduct(item); ok {
+				return product, true
+			}
+		}
+	case map[string]interface{}:
+		if hasJSONLDType(node, "Product") {
+			return node, true
+		}
+		if graph, ok := node["@graph"]; ok {
+			return findJSONLDProduct(graph)
+		}
+	}
+
+	return nil, false
+}
+
+func hasJSONLDType(node map[string]interface{}, schemaType string) bool {
+	for _, t := range jsonList(node["@type"]) {
+		if jsonString(t) == schemaType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jsonLDResult reads the cheapest of the product offers. AggregateOffers give
+// their lowPrice
+func jsonLDResult(product map[string]interface{}) crawlerparser.ProtocolResult {
+	result := crawlerparser.ProtocolResult{Link: jsonString(product["url"])}
+
+	for _, item := range jsonList(product["offers"]) {
+		offer, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		price, ok := parseSchemaPrice(jsonString(offer["price"]))
+		if !ok {
+			price, ok = parseSchemaPrice(jsonString(offer["lowPrice"]))
+		}
+		specPrice, listPrice := priceSpecification(offer["priceSpecification"])
+		if !ok && specPrice != nil {
+			price, ok = *specPrice, true
+		}
+		if ok && result.Price != nil && *result.Price <= price {
+			continue
+		}
+		if !ok && result.Price != nil {
+			continue
+		}
+
+		offerResult := crawlerparser.ProtocolResult{
+			OriginalPrice: listPrice,
+			Currency:      jsonString(offer["priceCurrency"]),
+			Availability:  parseAvailability(jsonString(offer["availability"])),
+			Seller:        jsonLDName(offer["seller"]),
+			Link:          result.Link,
+		}
+		if ok {
+			offerResult.Price = &price
+		}
+		if offerResult.Link == "" {
+			offerResult.Link = jsonString(offer["url"])
+		}
+		result = offerResult
+	}
+
+	return result
+}
+
+// priceSpecification returns the sale and list prices of an offer
+// priceSpecification, telling them apart by their priceType
+func priceSpecification(data interface{}) (*int, *int) {
+	var price, listPrice *int
+	for _, item := range jsonList(data) {
+		spec, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		value, ok := parseSchemaPrice(jsonString(spec["price"]))
+		if !ok {
+			continue
+		}
+
+		priceType := schemaName(jsonString(spec["priceType"]))
+		if priceType == "listprice" || priceType == "strikethroughprice" || priceType == "msrp" {
+			listPrice = &value
+		} else if price == nil {
+			price = &value
+		}
+	}
+
+	return price, listPrice
+}
+
+// jsonLDName name of a seller, either an Organization node or a plain string
+func jsonLDName(data interface{}) string {
+	if node, ok := data.(map[string]interface{}); ok {
+		return jsonString(node["name"])
+	}
+
+	return jsonString(data)
+}
+
+func microdataProduct(page *Page) crawlerparser.ProtocolResult {
+	result := crawlerparser.ProtocolResult{}
+
+	scope := page.Find(`[itemscope][itemtype*="schema.org/Product"]`).First()
+	if scope.Length() == 0 {
+		return result
+	}
+
+	if price, ok := parseSchemaPrice(itemprop(scope, "price")); ok {
+		result.Price = &price
+	} else if price, ok := parseSchemaPrice(itemprop(scope, "lowPrice")); ok {
+		result.Price = &price
+	}
+	result.Currency = itemprop(scope, "priceCurrency")
+	result.Availability = parseAvailability(itemprop(scope, "availability"))
+	result.Link = itemprop(scope, "url")
+
+	seller := scope.Find(`[itemprop="seller"]`).First()
+	if name := itemprop(seller, "name"); name != "" {
+		result.Seller = name
+	} else {
+		result.Seller = strings.TrimSpace(seller.Text())
+	}
+
+	return result
+}
+
+// itemprop value of the first microdata property with the name, read from the
+// attribute the microdata spec assigns to its element
+func itemprop(scope *goquery.Selection, name string) string {
+	prop := scope.Find(`[itemprop="` + name + `"]`).First()
+	if prop.Length() == 0 {
+		return ""
+	}
+
+	for _, attr := range []string{"content", "href", "src", "value"} {
+		if value, ok := prop.Attr(attr); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return strings.Join(strings.Fields(prop.Text()), " ")
+}
+
+func openGraphProduct(page *Page) crawlerparser.ProtocolResult {
+	result := crawlerparser.ProtocolResult{
+		Currency:     firstNonEmpty(page.Meta("product:price:currency"), page.Meta("og:price:currency")),
+		Availability: parseAvailability(firstNonEmpty(page.Meta("product:availability"), page.Meta("og:availability"))),
+		Link:         page.Meta("og:url"),
+	}
+	if price, ok := parseSchemaPrice(firstNonEmpty(page.Meta("product:price:amount"), page.Meta("og:price:amount"))); ok {
+		result.Price = &price
+	}
+	if price, ok := parseSchemaPrice(page.Meta("product:original_price:amount")); ok {
+		result.OriginalPrice = &price
+	}
+
+	return result
+}
+
+// parseAvailability protocol availability of a schema.org URL
+// ("https://schema.org/InStock"), a bare value or an OpenGraph value, "" when
+// unknown
+func parseAvailability(value string) string {
+	return schemaAvailability[schemaName(value)]
+}
+
+// schemaName lowercase name of a schema.org enumeration value, without the
+// schema.org prefix
+func schemaName(value string) string {
+	value = strings.TrimSpace(value)
+	if i := strings.LastIndex(value, "/"); i >= 0 {
+		value = value[i+1:]
+	}
+
+	return strings.ToLower(value)
+}
+
+// parseSchemaPrice reads a price in the machine format schema.org asks for,
+// with a dot as decimal separator, into cents
+func parseSchemaPrice(value string) (int, bool) {
+	price, err := entities.ParsePrice(value, entities.LocaleEnUS)
+
+	return price, err == nil
+}
+
+func absoluteLink(page *Page, link string) string {
+	if link == "" || page.Url == nil {
+		return link
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+
+	return page.Url.ResolveReference(parsed).String()
+}
+
+// jsonList values of a JSON-LD property, which may hold one value or a list
+func jsonList(data interface{}) []interface{} {
+	switch value := data.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return value
+	default:
+		return []interface{}{value}
+	}
+}
+
+func jsonString(data interface{}) string {
+	switch value := data.(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case json.Number:
+		return value.String()
+	default:
+		return ""
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,89 @@
+package native
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	crawlerparser "github.com/JoaoLeal92/product-monitor-orchestrator/infra/crawlerParser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func TestGeneric(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+
+	tests := map[string]struct {
+		page           string
+		expectedResult crawlerparser.ProtocolResult
+	}{
+		"json-ld": {
+			"/jsonld.html",
+			crawlerparser.ProtocolResult{
+				Price:         intPtr(29990),
+				OriginalPrice: intPtr(39990),
+				Currency:      "BRL",
+				Availability:  "limited",
+				Seller:        "Loja Exemplo",
+				Link:          "https://www.loja-exemplo.com.br/fone-bluetooth/p/987",
+			},
+		},
+		"microdata": {
+			"/microdata.html",
+			crawlerparser.ProtocolResult{
+				Price:        intPtr(18900),
+				Currency:     "BRL",
+				Availability: "out_of_stock",
+				Seller:       "Casa & Cozinha",
+				Link:         server.URL + "/cafeteira-eletrica",
+			},
+		},
+		"open-graph": {
+			"/opengraph.html",
+			crawlerparser.ProtocolResult{
+				Price:         intPtr(25950),
+				OriginalPrice: intPtr(29990),
+				Currency:      "BRL",
+				Availability:  "preorder",
+				Link:          "https://www.loja-exemplo.com.br/mochila-executiva",
+			},
+		},
+	}
+
+	client := NewClient(config.HTTPConfig{})
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			result, err := Generic.Crawl(context.Background(), client, entities.Product{Link: server.URL + testData.page})
+
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedResult, result)
+		})
+	}
+}
+
+func TestGenericWithoutProductData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><script type="application/ld+json">{"@type": "Product", </script></head></html>`))
+	}))
+	defer server.Close()
+
+	_, err := Generic.Crawl(context.Background(), NewClient(config.HTTPConfig{}), entities.Product{Link: server.URL})
+
+	assert.ErrorIs(t, err, entities.ErrLayoutChanged)
+}
+
+func TestParseAvailability(t *testing.T) {
+	assert.Equal(t, "in_stock", parseAvailability("http://schema.org/InStock"))
+	assert.Equal(t, "out_of_stock", parseAvailability("OutOfStock"))
+	assert.Equal(t, "out_of_stock", parseAvailability("out of stock"))
+	assert.Equal(t, "unavailable", parseAvailability("https://schema.org/Discontinued"))
+	assert.Equal(t, "", parseAvailability("https://schema.org/Unknown"))
+}
@@ -0,0 +1,242 @@
+package crawler
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/contracts"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	crawlerparser "github.com/JoaoLeal92/product-monitor-orchestrator/infra/crawlerParser"
+)
+
+// Registry crawlers declared in [[crawlers.sites]], looked up by name or by
+// the domains of the links they read
+type Registry struct {
+	sites   map[string]config.CrawlerSiteConfig
+	domains map[string]string
+}
+
+// NewRegistry validates the crawler entries, with their output formats looked
+// up in parsers, and builds the registry
+func NewRegistry(cfg *config.CrawlerConfig, parsers contracts.ResultParserRegistry) (*Registry, error) {
+	if err := validateRetry(cfg.Retry); err != nil {
+		return &Registry{}, err
+	}
+	if err := validateCircuitBreaker(cfg.CircuitBreaker); err != nil {
+		return &Registry{}, err
+	}
+
+	sites := make(map[string]config.CrawlerSiteConfig, len(cfg.Sites))
+	domains := make(map[string]string)
+	for i, site := range cfg.Sites {
+		if site.Name == "" {
+			return &Registry{}, fmt.Errorf("crawler entry %d has no name", i)
+		}
+		if _, ok := sites[site.Name]; ok {
+			return &Registry{}, fmt.Errorf("crawler %q declared more than once", site.Name)
+		}
+		if err := validateType(site); err != nil {
+			return &Registry{}, fmt.Errorf("crawler %q: %w", site.Name, err)
+		}
+		if err := validateOutputFormat(site, parsers); err != nil {
+			return &Registry{}, fmt.Errorf("crawler %q: %w", site.Name, err)
+		}
+		if err := validateMode(site); err != nil {
+			return &Registry{}, fmt.Errorf("crawler %q: %w", site.Name, err)
+		}
+		if err := validateRetry(site.Retry); err != nil {
+			return &Registry{}, fmt.Errorf("crawler %q: %w", site.Name, err)
+		}
+		if err := validateCircuitBreaker(site.CircuitBreaker); err != nil {
+			return &Registry{}, fmt.Errorf("crawler %q: %w", site.Name, err)
+		}
+		if err := validateTemplates(site); err != nil {
+			return &Registry{}, fmt.Errorf("crawler %q: %w", site.Name, err)
+		}
+		for _, domain := range site.Domains {
+			domain = strings.ToLower(strings.TrimSpace(domain))
+			if domain == "" {
+				return &Registry{}, fmt.Errorf("crawler %q: empty domain", site.Name)
+			}
+			if other, ok := domains[domain]; ok {
+				return &Registry{}, fmt.Errorf("crawler %q: domain %q already read by crawler %q", site.Name, domain, other)
+			}
+			domains[domain] = site.Name
+		}
+
+		sites[site.Name] = site
+	}
+	if _, ok := sites[cfg.FallbackCrawler]; cfg.FallbackCrawler != "" && !ok {
+		return &Registry{}, fmt.Errorf("fallback-crawler %q is not declared in crawlers.sites", cfg.FallbackCrawler)
+	}
+
+	return &Registry{
+		sites:   sites,
+		domains: domains,
+	}, nil
+}
+
+// Site returns the crawler entry with the given name
+func (r *Registry) Site(crawlerName string) (config.CrawlerSiteConfig, error) {
+	site, ok := r.sites[crawlerName]
+	if !ok {
+		return config.CrawlerSiteConfig{}, fmt.Errorf("%w: %q", entities.ErrUnknownCrawler, crawlerName)
+	}
+	if !site.IsEnabled() {
+		return config.CrawlerSiteConfig{}, fmt.Errorf("%w: %q", entities.ErrCrawlerDisabled, crawlerName)
+	}
+
+	return site, nil
+}
+
+// CrawlerForLink returns the crawler declaring the domain of the link, the
+// most specific one when both a domain and its subdomain are declared
+func (r *Registry) CrawlerForLink(link string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || parsed.Hostname() == "" {
+		return "", fmt.Errorf("%w: %q", entities.ErrUnsupportedStore, link)
+	}
+	host := strings.ToLower(parsed.Hostname())
+
+	crawlerName, matched := "", ""
+	for domain, name := range r.domains {
+		if entities.HostInDomain(host, domain) && len(domain) > len(matched) {
+			crawlerName, matched = name, domain
+		}
+	}
+	if crawlerName == "" {
+		return "", fmt.Errorf("%w: %q", entities.ErrUnsupportedStore, host)
+	}
+
+	return crawlerName, nil
+}
+
+func validateType(site config.CrawlerSiteConfig) error {
+	switch site.Type {
+	case "", config.CrawlerTypeExec:
+		if site.Command == "" {
+			return errors.New("no command")
+		}
+	case config.CrawlerTypeNative:
+		if site.IsPersistent() {
+			return errors.New("native crawlers can not be persistent")
+		}
+	case config.CrawlerTypeSelector:
+		if site.IsPersistent() {
+			return errors.New("selector crawlers can not be persistent")
+		}
+		return validateSelectors(site.Selectors)
+	default:
+		return fmt.Errorf("unknown type %q", site.Type)
+	}
+
+	return nil
+}
+
+// validateOutputFormat checks a parser is registered for the format. Native
+// crawlers print the JSON protocol line, so only exec crawlers may declare
+// another format
+func validateOutputFormat(site config.CrawlerSiteConfig, parsers contracts.ResultParserRegistry) error {
+	if _, err := parsers.Parser(site.OutputFormat); err != nil {
+		return fmt.Errorf("output-format: %w", err)
+	}
+
+	switch site.OutputFormat {
+	case "", crawlerparser.FormatAuto, crawlerparser.FormatJSON:
+	default:
+		if site.IsNative() {
+			return fmt.Errorf("output-format %q is not supported by %s crawlers", site.OutputFormat, site.Type)
+		}
+	}
+
+	return nil
+}
+
+func validateSelectors(selectors config.SelectorConfig) error {
+	if !selectors.Price.IsSet() {
+		return errors.New("no price selector")
+	}
+	if _, err := regexp.Compile(selectors.URLPattern); err != nil {
+		return fmt.Errorf("invalid url-pattern: %w", err)
+	}
+
+	switch selectors.Format {
+	case "", config.SelectorFormatHTML:
+	case config.SelectorFormatJSON:
+		for _, field := range []config.FieldSelector{selectors.Price, selectors.OriginalPrice, selectors.Discount, selectors.Stock} {
+			if field.CSS != "" {
+				return errors.New("css selectors can not be used with json pages")
+			}
+		}
+	default:
+		return fmt.Errorf("unknown selectors format %q", selectors.Format)
+	}
+
+	switch selectors.PriceLocale {
+	case "", entities.LocalePtBR, entities.LocaleEnUS:
+	default:
+		return fmt.Errorf("unsupported price-locale %q", selectors.PriceLocale)
+	}
+
+	return nil
+}
+
+func validateMode(site config.CrawlerSiteConfig) error {
+	switch site.Mode {
+	case "", config.CrawlerModeExec, config.CrawlerModePersistent:
+	default:
+		return fmt.Errorf("unknown mode %q", site.Mode)
+	}
+
+	if site.Workers < 0 || site.MaxRequests < 0 || site.BatchSize < 0 {
+		return errors.New("workers, max-requests and batch-size can not be negative")
+	}
+
+	return nil
+}
+
+func validateRetry(retry config.RetryConfig) error {
+	for _, kind := range retry.RetryOn {
+		if entities.ParseFailureKind(kind) == entities.FailureUnknown && kind != string(entities.FailureUnknown) {
+			return fmt.Errorf("unknown failure kind %q in retry-on", kind)
+		}
+	}
+
+	return nil
+}
+
+func validateCircuitBreaker(breaker config.CircuitBreakerConfig) error {
+	if breaker.FailureRatio < 0 || breaker.FailureRatio > 1 {
+		return fmt.Errorf("circuit breaker failure-ratio %v is not between 0 and 1", breaker.FailureRatio)
+	}
+
+	return nil
+}
+
+func validateTemplates(site config.CrawlerSiteConfig) error {
+	_, err := renderTemplates(site.Args, commandTemplateData{})
+	if err != nil {
+		return fmt.Errorf("invalid args template: %w", err)
+	}
+
+	_, err = renderTemplates(site.WorkerArgs, commandTemplateData{})
+	if err != nil {
+		return fmt.Errorf("invalid worker-args template: %w", err)
+	}
+
+	_, err = renderTemplates(site.BatchArgs, commandTemplateData{})
+	if err != nil {
+		return fmt.Errorf("invalid batch-args template: %w", err)
+	}
+
+	_, err = renderTemplates(site.Env, commandTemplateData{})
+	if err != nil {
+		return fmt.Errorf("invalid env template: %w", err)
+	}
+
+	return nil
+}
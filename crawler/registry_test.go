@@ -0,0 +1,144 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	mocks "github.com/JoaoLeal92/product-monitor-orchestrator/contracts/mocks"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	crawlerparser "github.com/JoaoLeal92/product-monitor-orchestrator/infra/crawlerParser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	disabled := false
+	registry, err := NewRegistry(&config.CrawlerConfig{
+		Sites: []config.CrawlerSiteConfig{
+			{Name: "amazon", Command: "pipenv", Args: []string{"run", "python", ".", "-u", "{{.Link}}"}, OutputFormat: "kv"},
+			{Name: "kabum", Command: "pipenv", Enabled: &disabled},
+			{Name: "kabum-go", Type: config.CrawlerTypeNative},
+			{Name: "loja", Type: config.CrawlerTypeSelector, Selectors: config.SelectorConfig{Price: config.FieldSelector{CSS: ".price"}}},
+		},
+	}, crawlerparser.NewRegistry())
+	require.NoError(t, err)
+
+	site, err := registry.Site("amazon")
+	require.NoError(t, err)
+	assert.Equal(t, "pipenv", site.Command)
+
+	site, err = registry.Site("kabum-go")
+	require.NoError(t, err)
+	assert.True(t, site.IsNative())
+
+	_, err = registry.Site("kabum")
+	assert.ErrorIs(t, err, entities.ErrCrawlerDisabled)
+
+	_, err = registry.Site("mercado-livre")
+	assert.ErrorIs(t, err, entities.ErrUnknownCrawler)
+}
+
+func TestRegistryValidation(t *testing.T) {
+	tests := map[string][]config.CrawlerSiteConfig{
+		"missing-name":           {{Command: "pipenv"}},
+		"missing-command":        {{Name: "amazon"}},
+		"duplicated-name":        {{Name: "amazon", Command: "pipenv"}, {Name: "amazon", Command: "python"}},
+		"unknown-arg-field":      {{Name: "amazon", Command: "pipenv", Args: []string{"{{.Url}}"}}},
+		"invalid-env":            {{Name: "amazon", Command: "pipenv", Env: []string{"KEY={{.Link"}}},
+		"unknown-type":           {{Name: "amazon", Command: "pipenv", Type: "plugin"}},
+		"persistent-native":      {{Name: "amazon", Type: config.CrawlerTypeNative, Mode: config.CrawlerModePersistent}},
+		"selector-without-price": {{Name: "loja", Type: config.CrawlerTypeSelector}},
+		"invalid-url-pattern":    {{Name: "loja", Type: config.CrawlerTypeSelector, Selectors: config.SelectorConfig{URLPattern: "(", Price: config.FieldSelector{CSS: ".price"}}}},
+		"css-in-json-selectors":  {{Name: "loja", Type: config.CrawlerTypeSelector, Selectors: config.SelectorConfig{Format: config.SelectorFormatJSON, Price: config.FieldSelector{CSS: ".price"}}}},
+		"unknown-price-locale":   {{Name: "loja", Type: config.CrawlerTypeSelector, Selectors: config.SelectorConfig{PriceLocale: "de-DE", Price: config.FieldSelector{CSS: ".price"}}}},
+		"unknown-output-format":  {{Name: "amazon", Command: "pipenv", OutputFormat: "xml"}},
+		"kv-output-native":       {{Name: "amazon", Type: config.CrawlerTypeNative, OutputFormat: "kv"}},
+		"unknown-mode":           {{Name: "amazon", Command: "pipenv", Mode: "daemon"}},
+		"negative-workers":       {{Name: "amazon", Command: "pipenv", Mode: config.CrawlerModePersistent, Workers: -1}},
+		"invalid-breaker":        {{Name: "amazon", Command: "pipenv", CircuitBreaker: config.CircuitBreakerConfig{FailureRatio: 50}}},
+		"unknown-retry-on":       {{Name: "amazon", Command: "pipenv", Retry: config.RetryConfig{RetryOn: []string{"server_error"}}}},
+		"duplicated-domain":      {{Name: "amazon", Command: "pipenv", Domains: []string{"amazon.com.br"}}, {Name: "amazon-go", Type: config.CrawlerTypeNative, Domains: []string{"Amazon.com.br"}}},
+		"empty-domain":           {{Name: "amazon", Command: "pipenv", Domains: []string{""}}},
+	}
+
+	for testName, sites := range tests {
+		t.Run(testName, func(t *testing.T) {
+			_, err := NewRegistry(&config.CrawlerConfig{Sites: sites}, crawlerparser.NewRegistry())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRegistryCrawlerForLink(t *testing.T) {
+	registry, err := NewRegistry(&config.CrawlerConfig{
+		Sites: []config.CrawlerSiteConfig{
+			{Name: "mercado-livre", Command: "pipenv", Domains: []string{"mercadolivre.com.br"}},
+			{Name: "mercado-livre-produto", Command: "pipenv", Domains: []string{"produto.mercadolivre.com.br"}},
+			{Name: "generic", Type: config.CrawlerTypeNative},
+		},
+	}, crawlerparser.NewRegistry())
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		link            string
+		expectedCrawler string
+	}{
+		"domain":          {"https://mercadolivre.com.br/p/MLB19098287", "mercado-livre"},
+		"subdomain":       {"https://www.mercadolivre.com.br/p/MLB19098287", "mercado-livre"},
+		"specific-domain": {"https://PRODUTO.mercadolivre.com.br/MLB-1234567890", "mercado-livre-produto"},
+		"unsupported":     {"https://www.kabum.com.br/produto/123456", ""},
+		"not-a-link":      {"not a link", ""},
+		"similar-domain":  {"https://notmercadolivre.com.br/p/MLB19098287", ""},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			crawlerName, err := registry.CrawlerForLink(testData.link)
+
+			if testData.expectedCrawler == "" {
+				assert.ErrorIs(t, err, entities.ErrUnsupportedStore)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedCrawler, crawlerName)
+		})
+	}
+}
+
+func TestRegistryUnknownFallbackCrawler(t *testing.T) {
+	_, err := NewRegistry(&config.CrawlerConfig{
+		FallbackCrawler: "generic",
+		Sites:           []config.CrawlerSiteConfig{{Name: "amazon", Command: "pipenv"}},
+	}, crawlerparser.NewRegistry())
+
+	assert.Error(t, err)
+}
+
+func TestRegistryRegisteredOutputFormat(t *testing.T) {
+	parsers := crawlerparser.NewRegistry()
+	parsers.Register("xml", mocks.NewResultParser(t))
+
+	_, err := NewRegistry(&config.CrawlerConfig{
+		Sites: []config.CrawlerSiteConfig{{Name: "amazon", Command: "pipenv", OutputFormat: "xml"}},
+	}, parsers)
+
+	assert.NoError(t, err)
+}
+
+func TestBuildCommand(t *testing.T) {
+	site := config.CrawlerSiteConfig{
+		Name:       "amazon",
+		Command:    "pipenv",
+		Args:       []string{"run", "python", ".", "-u", "{{.Link}}"},
+		WorkingDir: "/opt/crawlers/amazon",
+		Env:        []string{"PIPENV_PIPFILE={{.WorkingDir}}/Pipfile"},
+	}
+	product := entities.Product{Link: "https://www.amazon.com.br/dp/B000000000"}
+
+	cmd, err := buildCommand(site, product)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pipenv", "run", "python", ".", "-u", "https://www.amazon.com.br/dp/B000000000"}, cmd.Args)
+	assert.Equal(t, "/opt/crawlers/amazon", cmd.Dir)
+	assert.Contains(t, cmd.Env, "PIPENV_PIPFILE=/opt/crawlers/amazon/Pipfile")
+}
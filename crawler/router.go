@@ -0,0 +1,44 @@
+package crawler
+
+import (
+	"context"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/contracts"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/google/uuid"
+)
+
+// Router runs each product with the crawler implementation of its site type,
+// so exec and native crawlers are registered side by side
+type Router struct {
+	exec   contracts.Crawler
+	native contracts.Crawler
+}
+
+func NewRouter(exec contracts.Crawler, native contracts.Crawler) *Router {
+	return &Router{
+		exec:   exec,
+		native: native,
+	}
+}
+
+func (r *Router) SetupCrawlerEnv(ctx context.Context, site config.CrawlerSiteConfig, productID string) error {
+	return r.crawler(site).SetupCrawlerEnv(ctx, site, productID)
+}
+
+func (r *Router) RunCrawler(ctx context.Context, site config.CrawlerSiteConfig, product entities.Product) (string, error) {
+	return r.crawler(site).RunCrawler(ctx, site, product)
+}
+
+func (r *Router) RunCrawlerBatch(ctx context.Context, site config.CrawlerSiteConfig, products []entities.Product) (map[uuid.UUID]string, error) {
+	return r.crawler(site).RunCrawlerBatch(ctx, site, products)
+}
+
+func (r *Router) crawler(site config.CrawlerSiteConfig) contracts.Crawler {
+	if site.IsNative() {
+		return r.native
+	}
+
+	return r.exec
+}
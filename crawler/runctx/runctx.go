@@ -0,0 +1,47 @@
+// Package runctx bounds a crawler run by the timeout of its crawler entry,
+// for the exec and the native crawlers alike
+package runctx
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+)
+
+// Timeout of the crawler entry, falling back to the default of cfg. Zero
+// means no timeout
+func Timeout(cfg *config.CrawlerConfig, site config.CrawlerSiteConfig) time.Duration {
+	if site.Timeout > 0 {
+		return site.Timeout
+	}
+
+	return cfg.Timeout
+}
+
+// WithTimeout returns the context of a run, bounded by timeout when it is set
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+// Err returns the error of ctx when the run was interrupted, a FailureTimeout
+// CrawlerError when only runCtx ran out of time and nil otherwise
+func Err(ctx context.Context, runCtx context.Context, timeout time.Duration) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if runCtx.Err() != nil {
+		return &entities.CrawlerError{
+			Kind:    entities.FailureTimeout,
+			Message: fmt.Sprintf("no result after %v", timeout),
+		}
+	}
+
+	return nil
+}
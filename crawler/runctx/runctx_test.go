@@ -0,0 +1,47 @@
+package runctx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	tests := map[string]struct {
+		site            config.CrawlerSiteConfig
+		expectedTimeout time.Duration
+	}{
+		"site-timeout":    {config.CrawlerSiteConfig{Timeout: time.Second}, time.Second},
+		"default-timeout": {config.CrawlerSiteConfig{}, time.Minute},
+	}
+
+	cfg := &config.CrawlerConfig{Timeout: time.Minute}
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expectedTimeout, Timeout(cfg, testData.site))
+		})
+	}
+}
+
+func TestErr(t *testing.T) {
+	runCtx, cancel := WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-runCtx.Done()
+
+	err := Err(context.Background(), runCtx, time.Nanosecond)
+	assert.Equal(t, entities.FailureTimeout, entities.FailureKindOf(err))
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	runCtx, cancel = WithTimeout(ctx, 0)
+	defer cancel()
+	cancelCtx()
+	assert.ErrorIs(t, Err(ctx, runCtx, 0), context.Canceled)
+
+	runCtx, cancel = WithTimeout(context.Background(), 0)
+	defer cancel()
+	assert.NoError(t, Err(context.Background(), runCtx, 0))
+}
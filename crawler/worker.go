@@ -0,0 +1,390 @@
+package crawler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/contracts"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/google/uuid"
+)
+
+// maxWorkerLineSize longest line accepted from a persistent worker
+const maxWorkerLineSize = 1024 * 1024
+
+// errWorkerExited worker process exited before answering a request
+var errWorkerExited = errors.New("crawler worker exited")
+
+// errWorkerPoolClosed request made after the crawler was closed
+var errWorkerPoolClosed = errors.New("crawler worker pool closed")
+
+// workerRequest line sent to a persistent worker for each product
+type workerRequest struct {
+	ID   string `json:"id"`
+	Link string `json:"link"`
+}
+
+// workerResponse id of the product a worker output line refers to, the rest
+// of the line follows the crawler output protocol
+type workerResponse struct {
+	ID string `json:"id"`
+}
+
+// worker long-lived crawler process reading products from stdin and writing
+// one result line per product to stdout
+type worker struct {
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	lines    chan string
+	done     chan struct{}
+	stopOnce sync.Once
+	exited   chan struct{}
+	stderr   *tailBuffer
+	requests int
+	// waitErr exit status of the process, set once exited is closed
+	waitErr error
+}
+
+// workerPool persistent workers of a crawler. Each slot holds a running worker
+// or nil, in which case a new one is started on demand
+type workerPool struct {
+	site   config.CrawlerSiteConfig
+	logger contracts.LoggerContract
+	slots  chan *worker
+	size   int
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func newWorkerPool(site config.CrawlerSiteConfig, workers int, logger contracts.LoggerContract) *workerPool {
+	if workers < 1 {
+		workers = 1
+	}
+
+	slots := make(chan *worker, workers)
+	for i := 0; i < workers; i++ {
+		slots <- nil
+	}
+
+	return &workerPool{
+		site:   site,
+		logger: logger,
+		slots:  slots,
+		size:   workers,
+	}
+}
+
+// crawl sends the products to an idle worker and returns their result lines
+// by product id. Workers that crash, time out or reach MaxRequests are
+// stopped and replaced on the next request
+func (p *workerPool) crawl(ctx context.Context, products []entities.Product) (map[uuid.UUID]string, error) {
+	var w *worker
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case w = <-p.slots:
+	}
+
+	if p.isClosed() {
+		p.release(w)
+		return nil, errWorkerPoolClosed
+	}
+	if w != nil && w.hasExited() {
+		p.logger.Warn(fmt.Sprintf("Worker do crawler %s encerrado inesperadamente, reiniciando: %s", p.site.Name, w.stderr.String()))
+		w = nil
+	}
+	if w == nil {
+		var err error
+		w, err = startWorker(p.site)
+		if err != nil {
+			p.release(nil)
+			return nil, err
+		}
+	}
+
+	outputs, err := w.crawl(ctx, products)
+	if err != nil {
+		if !w.hasExited() {
+			terminateProcessGroup(w.cmd)
+		}
+		w.stop()
+		p.release(nil)
+		if errors.Is(err, errWorkerExited) && w.waitErr != nil {
+			return outputs, exitFailure(w.waitErr, w.stderr.String())
+		}
+		if errors.Is(err, errWorkerExited) {
+			return outputs, exitFailure(err, w.stderr.String())
+		}
+		return outputs, err
+	}
+
+	if p.site.MaxRequests > 0 && w.requests >= p.site.MaxRequests {
+		p.logger.Info(fmt.Sprintf("Worker do crawler %s atingiu %d requisições, reiniciando", p.site.Name, w.requests))
+		w.stop()
+		w = nil
+	}
+	p.release(w)
+
+	return outputs, nil
+}
+
+// release gives a slot back to the pool, stopping its worker when the pool
+// is closed
+func (p *workerPool) release(w *worker) {
+	if w != nil && p.isClosed() {
+		w.stop()
+		w = nil
+	}
+	p.slots <- w
+}
+
+func (p *workerPool) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.closed
+}
+
+// close stops the idle workers and waits up to timeout for the busy ones to
+// finish their request and be stopped. Workers still busy after timeout are
+// stopped by release once their request is over
+func (p *workerPool) close(timeout time.Duration) {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	collected := 0
+	defer func() {
+		for i := 0; i < collected; i++ {
+			p.slots <- nil
+		}
+	}()
+
+	stop := func(w *worker) {
+		if w != nil {
+			w.stop()
+		}
+		collected++
+	}
+	for collected < p.size {
+		// idle workers first, so a zero timeout still stops them
+		select {
+		case w := <-p.slots:
+			stop(w)
+			continue
+		default:
+		}
+
+		select {
+		case w := <-p.slots:
+			stop(w)
+		case <-timer.C:
+			p.logger.Warn(fmt.Sprintf("Workers do crawler %s ainda ocupados após %v, serão encerrados ao fim da requisição", p.site.Name, timeout))
+			return
+		}
+	}
+}
+
+func startWorker(site config.CrawlerSiteConfig) (*worker, error) {
+	cmd, err := buildWorkerCommand(site)
+	if err != nil {
+		return nil, err
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr := &tailBuffer{limit: 4096}
+	cmd.Stderr = stderr
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &worker{
+		cmd:    cmd,
+		stdin:  stdin,
+		lines:  make(chan string),
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+		stderr: stderr,
+	}
+	go w.readLoop(stdout)
+
+	return w, nil
+}
+
+// readLoop forwards the stdout lines of the worker, dropping them once it is
+// stopped, and waits for the process to exit when stdout is closed
+func (w *worker) readLoop(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxWorkerLineSize)
+	for scanner.Scan() {
+		select {
+		case w.lines <- scanner.Text():
+		case <-w.done:
+		}
+	}
+	_, _ = io.Copy(io.Discard, stdout)
+
+	w.waitErr = w.cmd.Wait()
+	close(w.lines)
+	close(w.exited)
+}
+
+func (w *worker) hasExited() bool {
+	select {
+	case <-w.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+// crawl writes the products to the worker stdin and waits for the output
+// lines with their ids. Lines of other products and logs are skipped. On
+// failure the results received so far are returned along with the error
+func (w *worker) crawl(ctx context.Context, products []entities.Product) (map[uuid.UUID]string, error) {
+	pending := make(map[string]uuid.UUID, len(products))
+	for _, product := range products {
+		pending[product.ID.String()] = product.ID
+	}
+
+	// written apart from the reads, so a worker answering before it consumed
+	// the whole batch can not block on a full stdout pipe
+	writeErr := make(chan error, 1)
+	go func() {
+		writeErr <- writeRequests(w.stdin, products)
+	}()
+	w.requests += len(products)
+
+	outputs := make(map[uuid.UUID]string, len(products))
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return outputs, ctx.Err()
+		case err := <-writeErr:
+			if err != nil {
+				return outputs, fmt.Errorf("%w: %v", errWorkerExited, err)
+			}
+			writeErr = nil
+		case line, ok := <-w.lines:
+			if !ok {
+				return outputs, errWorkerExited
+			}
+
+			id, output, ok := parseWorkerLine(line)
+			if productID, requested := pending[id]; ok && requested {
+				outputs[productID] = output
+				delete(pending, id)
+			}
+		}
+	}
+
+	return outputs, nil
+}
+
+func writeRequests(out io.Writer, products []entities.Product) error {
+	for _, product := range products {
+		request, err := json.Marshal(workerRequest{ID: product.ID.String(), Link: product.Link})
+		if err != nil {
+			return err
+		}
+
+		_, err = out.Write(append(request, '\n'))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseWorkerLine returns the product id of an output line and the line
+// itself, ok is false for lines that are not JSON objects
+func parseWorkerLine(line string) (string, string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") {
+		return "", "", false
+	}
+
+	response := workerResponse{}
+	if err := json.Unmarshal([]byte(trimmed), &response); err != nil {
+		return "", "", false
+	}
+
+	return response.ID, trimmed, true
+}
+
+// stop closes the worker stdin so it can exit on its own, terminating its
+// process group if it does not within killGracePeriod
+func (w *worker) stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+		_ = w.stdin.Close()
+	})
+
+	timer := time.NewTimer(killGracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-w.exited:
+		return
+	case <-timer.C:
+		terminateProcessGroup(w.cmd)
+	}
+
+	timer.Reset(killGracePeriod)
+	select {
+	case <-w.exited:
+	case <-timer.C:
+		killProcessGroup(w.cmd)
+		<-w.exited
+	}
+}
+
+// tailBuffer keeps the last bytes written to it, used to report why a worker
+// crashed
+type tailBuffer struct {
+	mu    sync.Mutex
+	limit int
+	buf   []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.limit {
+		t.buf = t.buf[len(t.buf)-t.limit:]
+	}
+
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return strings.TrimSpace(string(t.buf))
+}
@@ -0,0 +1,156 @@
+//go:build !windows
+// +build !windows
+
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	mocks "github.com/JoaoLeal92/product-monitor-orchestrator/contracts/mocks"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// echoWorker answers each request with its pid as the price, so tests can
+// tell when a worker was restarted. exitAfter > 0 makes it crash after that
+// many requests
+func echoWorker(exitAfter string) config.CrawlerSiteConfig {
+	script := `n=0
+while read -r line; do
+	id=$(echo "$line" | sed 's/.*"id":"\([^"]*\)".*/\1/')
+	echo "log line"
+	echo "{\"schema_version\":1,\"id\":\"$id\",\"price\":$$}"
+	n=$((n+1))
+	if [ "$n" = "$1" ]; then exit 1; fi
+done`
+
+	return config.CrawlerSiteConfig{
+		Name:    "echo",
+		Command: "sh",
+		Args:    []string{"-c", script, "worker", exitAfter},
+		Mode:    config.CrawlerModePersistent,
+	}
+}
+
+func workerPid(t *testing.T, output string) int {
+	var result struct {
+		Price int `json:"price"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &result))
+
+	return result.Price
+}
+
+func TestPersistentWorker(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockLogger.On("Info", mock.Anything).Return(nil)
+
+	site := echoWorker("0")
+	site.MaxRequests = 2
+	crawler := NewCrawler(&config.CrawlerConfig{NumCrawlers: 1, Timeout: 5 * time.Second}, mockLogger)
+	defer crawler.Close()
+
+	pids := []int{}
+	for i := 0; i < 3; i++ {
+		output, err := crawler.RunCrawler(context.Background(), site, entities.Product{ID: uuid.New()})
+		require.NoError(t, err)
+		pids = append(pids, workerPid(t, output))
+	}
+
+	assert.Equal(t, pids[0], pids[1])
+	assert.NotEqual(t, pids[1], pids[2])
+}
+
+func TestPersistentWorkerRestartsAfterCrash(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Warn", mock.Anything).Return(nil)
+
+	crawler := NewCrawler(&config.CrawlerConfig{NumCrawlers: 1, Timeout: 5 * time.Second}, mockLogger)
+	defer crawler.Close()
+
+	site := echoWorker("1")
+	first, err := crawler.RunCrawler(context.Background(), site, entities.Product{ID: uuid.New()})
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	second, err := crawler.RunCrawler(context.Background(), site, entities.Product{ID: uuid.New()})
+	require.NoError(t, err)
+
+	assert.NotEqual(t, workerPid(t, first), workerPid(t, second))
+}
+
+func TestPersistentWorkerTimeout(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Warn", mock.Anything).Return(nil)
+
+	crawler := NewCrawler(&config.CrawlerConfig{NumCrawlers: 1, Timeout: 100 * time.Millisecond}, mockLogger)
+	defer crawler.Close()
+
+	site := config.CrawlerSiteConfig{Name: "silent", Command: "sh", Args: []string{"-c", "cat > /dev/null"}, Mode: config.CrawlerModePersistent}
+	_, err := crawler.RunCrawler(context.Background(), site, entities.Product{ID: uuid.New()})
+
+	assert.ErrorIs(t, err, entities.ErrCrawlerTimeout)
+}
+
+func TestCloseStopsBusyWorkers(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockLogger.On("Info", mock.Anything).Return(nil)
+
+	crawler := NewCrawler(&config.CrawlerConfig{NumCrawlers: 1, Timeout: 5 * time.Second, ShutdownTimeout: 5 * time.Second}, mockLogger)
+	site := echoWorker("0")
+	site.Args[1] = "sleep 0.3\n" + site.Args[1]
+
+	output := make(chan string, 1)
+	go func() {
+		out, err := crawler.RunCrawler(context.Background(), site, entities.Product{ID: uuid.New()})
+		assert.NoError(t, err)
+		output <- out
+	}()
+	time.Sleep(100 * time.Millisecond)
+	crawler.Close()
+
+	pid := workerPid(t, <-output)
+	assert.Equal(t, syscall.ESRCH, syscall.Kill(pid, 0))
+}
+
+func TestClosedWorkerPool(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+
+	pool := newWorkerPool(echoWorker("0"), 1, mockLogger)
+	pool.close(time.Second)
+	_, err := pool.crawl(context.Background(), []entities.Product{{ID: uuid.New()}})
+
+	assert.ErrorIs(t, err, errWorkerPoolClosed)
+}
+
+func TestRunCrawlerBatch(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockLogger.On("Info", mock.Anything).Return(nil)
+
+	crawler := NewCrawler(&config.CrawlerConfig{NumCrawlers: 1, Timeout: 5 * time.Second}, mockLogger)
+	defer crawler.Close()
+
+	products := []entities.Product{{ID: uuid.New()}, {ID: uuid.New()}}
+	for _, mode := range []string{config.CrawlerModeExec, config.CrawlerModePersistent} {
+		t.Run(mode, func(t *testing.T) {
+			site := echoWorker("0")
+			site.Mode = mode
+			site.BatchSize = 2
+
+			outputs, err := crawler.RunCrawlerBatch(context.Background(), site, products)
+
+			require.NoError(t, err)
+			assert.Len(t, outputs, 2)
+			assert.Equal(t, workerPid(t, outputs[products[0].ID]), workerPid(t, outputs[products[1].ID]))
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package data
 
 import (
+	"context"
+	"time"
+
 	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -17,7 +21,7 @@ func NewProductsRepository(conn *gorm.DB) *ProductRepo {
 	}
 }
 
-func (r *ProductRepo) GetProductsListForCrawler() ([]entities.Product, error) {
+func (r *ProductRepo) GetProductsListForCrawler(ctx context.Context) ([]entities.Product, error) {
 	var products []entities.Product
 	query := `
 		SELECT 
@@ -26,20 +30,168 @@ func (r *ProductRepo) GetProductsListForCrawler() ([]entities.Product, error) {
 			pr.description,
 			pr.max_price,
 			pr.link,
-			cr.name crawler_name
+			pr.rules,
+			COALESCE(cr.name, '') crawler_name
 		FROM users u
 		JOIN products pr
 				ON u.id = pr.user_id
-		JOIN crawlers cr
+		LEFT JOIN crawlers cr
 				ON pr.crawler_id = cr.id
 		WHERE u.active = 1
 		AND pr.active = true 
 	`
 
-	result := r.db.Raw(query).Scan(&products)
+	result := r.db.WithContext(ctx).Raw(query).Scan(&products)
 	if result.Error != nil {
 		return []entities.Product{}, result.Error
 	}
 
 	return products, nil
 }
+
+// dueProductsCondition selects the products whose check interval has elapsed
+// since their last check. The interval falls back to the crawler default and
+// then to the @default_interval argument (minutes)
+const dueProductsCondition = `
+		(
+			pr.last_checked_at IS NULL
+			OR pr.last_checked_at + make_interval(mins => COALESCE(pr.check_interval, cr.default_check_interval, @default_interval)) <= now()
+		)
+`
+
+// GetDueProductsForCrawler returns the active products whose check interval
+// has elapsed since their last check. Products without a crawler come with an
+// empty CrawlerName, to be detected from their link
+func (r *ProductRepo) GetDueProductsForCrawler(ctx context.Context, defaultCheckInterval time.Duration) ([]entities.Product, error) {
+	var products []entities.Product
+	query := `
+		SELECT 
+			u.id user_id,
+			pr.id,
+			pr.description,
+			pr.max_price,
+			pr.link,
+			pr.rules,
+			COALESCE(cr.name, '') crawler_name,
+			COALESCE(pr.check_interval, cr.default_check_interval, @default_interval) check_interval,
+			pr.last_checked_at
+		FROM users u
+		JOIN products pr
+				ON u.id = pr.user_id
+		LEFT JOIN crawlers cr
+				ON pr.crawler_id = cr.id
+		WHERE u.active = 1
+		AND pr.active = true 
+		AND ` + dueProductsCondition
+
+	args := map[string]interface{}{
+		"default_interval": int(defaultCheckInterval.Minutes()),
+	}
+	result := r.db.WithContext(ctx).Raw(query, args).Scan(&products)
+	if result.Error != nil {
+		return []entities.Product{}, result.Error
+	}
+
+	return products, nil
+}
+
+// ClaimDueProducts leases up to batchSize due products to owner. Products
+// leased by other owners are skipped until their lease expires, so several
+// orchestrators can share the same catalog without crawling a product twice
+func (r *ProductRepo) ClaimDueProducts(ctx context.Context, owner string, batchSize int, leaseDuration time.Duration, defaultCheckInterval time.Duration) ([]entities.Product, error) {
+	var products []entities.Product
+	query := `
+		WITH claimed AS (
+			UPDATE products
+			SET lease_owner = @owner,
+				lease_expires_at = now() + make_interval(secs => @lease_seconds)
+			WHERE id IN (
+				SELECT pr.id
+				FROM users u
+				JOIN products pr
+						ON u.id = pr.user_id
+				LEFT JOIN crawlers cr
+						ON pr.crawler_id = cr.id
+				WHERE u.active = 1
+				AND pr.active = true
+				AND (pr.lease_expires_at IS NULL OR pr.lease_expires_at < now())
+				AND ` + dueProductsCondition + `
+				ORDER BY pr.last_checked_at NULLS FIRST
+				LIMIT @batch_size
+				FOR UPDATE OF pr SKIP LOCKED
+			)
+			RETURNING id
+		)
+		SELECT 
+			u.id user_id,
+			pr.id,
+			pr.description,
+			pr.max_price,
+			pr.link,
+			pr.rules,
+			COALESCE(cr.name, '') crawler_name,
+			COALESCE(pr.check_interval, cr.default_check_interval, @default_interval) check_interval,
+			pr.last_checked_at
+		FROM claimed cl
+		JOIN products pr
+				ON cl.id = pr.id
+		JOIN users u
+				ON u.id = pr.user_id
+		LEFT JOIN crawlers cr
+				ON pr.crawler_id = cr.id
+	`
+
+	args := map[string]interface{}{
+		"owner":            owner,
+		"lease_seconds":    leaseDuration.Seconds(),
+		"batch_size":       batchSize,
+		"default_interval": int(defaultCheckInterval.Minutes()),
+	}
+	result := r.db.WithContext(ctx).Raw(query, args).Scan(&products)
+	if result.Error != nil {
+		return []entities.Product{}, result.Error
+	}
+
+	return products, nil
+}
+
+// RenewLeases extends the leases owner still holds on the given products
+func (r *ProductRepo) RenewLeases(ctx context.Context, owner string, productIDs []uuid.UUID, leaseDuration time.Duration) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	result := r.db.WithContext(ctx).Model(&entities.Product{}).
+		Where("id IN ? AND lease_owner = ?", productIDs, owner).
+		Update("lease_expires_at", gorm.Expr("now() + make_interval(secs => ?)", leaseDuration.Seconds()))
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+// ReleaseLeases gives back the leases owner holds on the given products
+func (r *ProductRepo) ReleaseLeases(ctx context.Context, owner string, productIDs []uuid.UUID) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	result := r.db.WithContext(ctx).Model(&entities.Product{}).
+		Where("id IN ? AND lease_owner = ?", productIDs, owner).
+		Updates(map[string]interface{}{"lease_owner": nil, "lease_expires_at": nil})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *ProductRepo) UpdateLastCheckedAt(ctx context.Context, productID uuid.UUID, checkedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&entities.Product{}).Where("id = ?", productID).Update("last_checked_at", checkedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"errors"
 
 	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
@@ -20,8 +21,8 @@ func NewProductSearchHistoryRepository(conn *gorm.DB) *ProductSearchHisotry {
 	}
 }
 
-func (r *ProductSearchHisotry) InsertNewHistory(productSearch *entities.ProductSearchResult) error {
-	result := r.db.Create(&productSearch)
+func (r *ProductSearchHisotry) InsertNewHistory(ctx context.Context, productSearch *entities.ProductSearchResult) error {
+	result := r.db.WithContext(ctx).Create(&productSearch)
 
 	if result.Error != nil {
 		return errors.New(result.Error.Error())
@@ -29,10 +30,10 @@ func (r *ProductSearchHisotry) InsertNewHistory(productSearch *entities.ProductS
 	return nil
 }
 
-func (r *ProductSearchHisotry) GetHistoryByProductID(productID uuid.UUID) ([]entities.ProductSearchResult, error) {
+func (r *ProductSearchHisotry) GetHistoryByProductID(ctx context.Context, productID uuid.UUID) ([]entities.ProductSearchResult, error) {
 	var searchHistory []entities.ProductSearchResult
 
-	result := r.db.Where("product_id = ?", productID).Find(&searchHistory)
+	result := r.db.WithContext(ctx).Where("product_id = ?", productID).Find(&searchHistory)
 	if result.Error != nil {
 		return []entities.ProductSearchResult{}, result.Error
 	}
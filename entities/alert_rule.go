@@ -0,0 +1,137 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// AlertRuleType condition of an alert rule
+type AlertRuleType string
+
+const (
+	// RuleTargetPrice the price is at or below Target
+	RuleTargetPrice AlertRuleType = "target_price"
+	// RuleBelowAverage the price is at least Percent below the average of the
+	// last Window checks, or of every check when Window is 0
+	RuleBelowAverage AlertRuleType = "below_average"
+	// RuleAllTimeLow the price is lower than in every previous check
+	RuleAllTimeLow AlertRuleType = "all_time_low"
+	// RuleDropSinceLastCheck the price dropped by at least Percent since the
+	// previous check with a price
+	RuleDropSinceLastCheck AlertRuleType = "drop_since_last_check"
+	// RuleNearTarget the price is at most Percent above Target
+	RuleNearTarget AlertRuleType = "near_target"
+)
+
+// AlertRule rule a check must match for the product to be notified. Target
+// is in cents, the same unit as Product.MaxPrice
+type AlertRule struct {
+	Type    AlertRuleType `json:"type"`
+	Target  int           `json:"target,omitempty"`
+	Percent float64       `json:"percent,omitempty"`
+	Window  int           `json:"window,omitempty"`
+	// err why the rule read from products.rules is invalid
+	err error
+}
+
+// Validate checks the rule has the fields its type needs
+func (r AlertRule) Validate() error {
+	if r.Percent < 0 || r.Window < 0 || r.Target < 0 {
+		return fmt.Errorf("%s rule with negative values", r.Type)
+	}
+
+	switch r.Type {
+	case RuleTargetPrice:
+		if r.Target == 0 {
+			return errors.New("target_price rule without target")
+		}
+	case RuleBelowAverage:
+		if r.Percent == 0 {
+			return errors.New("below_average rule without percent")
+		}
+	case RuleAllTimeLow, RuleDropSinceLastCheck:
+	case RuleNearTarget:
+		if r.Target == 0 || r.Percent == 0 {
+			return errors.New("near_target rule without target or percent")
+		}
+	default:
+		return fmt.Errorf("unknown alert rule type %q", r.Type)
+	}
+
+	return nil
+}
+
+// AlertRules rules of a product, stored as jsonb in products.rules
+type AlertRules []AlertRule
+
+// Scan reads the jsonb column, where NULL means no rules. The rules are
+// validated once here: invalid ones, or a value that is not a list of rules,
+// are left out of Valid and reported by Errors instead of failing the product
+func (r *AlertRules) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported alert rules value %T", value)
+	}
+
+	var rules AlertRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		*r = AlertRules{{err: fmt.Errorf("rules %s are not a list of alert rules: %w", data, err)}}
+		return nil
+	}
+	for i := range rules {
+		rules[i].err = rules[i].Validate()
+	}
+	*r = rules
+
+	return nil
+}
+
+// Valid rules, leaving out the ones found invalid when read
+func (r AlertRules) Valid() AlertRules {
+	var valid AlertRules
+	for _, rule := range r {
+		if rule.err == nil {
+			valid = append(valid, rule)
+		}
+	}
+
+	return valid
+}
+
+// Errors why the rules left out of Valid are invalid
+func (r AlertRules) Errors() []error {
+	var errs []error
+	for _, rule := range r {
+		if rule.err != nil {
+			errs = append(errs, rule.err)
+		}
+	}
+
+	return errs
+}
+
+// Value writes the valid rules as JSON for the jsonb column, NULL when there
+// are none
+func (r AlertRules) Value() (driver.Value, error) {
+	valid := r.Valid()
+	if valid == nil {
+		return nil, nil
+	}
+
+	value, err := json.Marshal(valid)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(value), nil
+}
@@ -0,0 +1,73 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertRuleValidate(t *testing.T) {
+	tests := map[string]struct {
+		rule          AlertRule
+		expectedValid bool
+	}{
+		"target-price":                {AlertRule{Type: RuleTargetPrice, Target: 1000}, true},
+		"target-price-without-target": {AlertRule{Type: RuleTargetPrice}, false},
+		"below-average":               {AlertRule{Type: RuleBelowAverage, Percent: 10, Window: 30}, true},
+		"below-average-no-percent":    {AlertRule{Type: RuleBelowAverage}, false},
+		"all-time-low":                {AlertRule{Type: RuleAllTimeLow}, true},
+		"drop-since-last-check":       {AlertRule{Type: RuleDropSinceLastCheck, Percent: 5}, true},
+		"negative-percent":            {AlertRule{Type: RuleDropSinceLastCheck, Percent: -5}, false},
+		"near-target":                 {AlertRule{Type: RuleNearTarget, Target: 1000, Percent: 5}, true},
+		"near-target-no-percent":      {AlertRule{Type: RuleNearTarget, Target: 1000}, false},
+		"unknown-type":                {AlertRule{Type: "price_drop"}, false},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			err := testData.rule.Validate()
+			assert.Equal(t, testData.expectedValid, err == nil)
+		})
+	}
+}
+
+func TestAlertRulesScan(t *testing.T) {
+	var rules AlertRules
+	err := rules.Scan([]byte(`[{"type": "target_price", "target": 1000}, {"type": "below_average", "percent": 10, "window": 30}]`))
+
+	require.NoError(t, err)
+	assert.Equal(t, AlertRules{
+		{Type: RuleTargetPrice, Target: 1000},
+		{Type: RuleBelowAverage, Percent: 10, Window: 30},
+	}, rules)
+
+	value, err := rules.Value()
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"type": "target_price", "target": 1000}, {"type": "below_average", "percent": 10, "window": 30}]`, value.(string))
+
+	require.NoError(t, rules.Scan(`[{"type": "all_time_low"}, {"type": "price_drop"}, {"type": "target_price"}]`))
+	assert.Equal(t, AlertRules{{Type: RuleAllTimeLow}}, rules.Valid())
+	assert.Len(t, rules.Errors(), 2)
+
+	require.NoError(t, rules.Scan(`{}`))
+	assert.Empty(t, rules.Valid())
+	assert.Len(t, rules.Errors(), 1)
+
+	require.NoError(t, rules.Scan(nil))
+	assert.Nil(t, rules)
+}
+
+func TestProductAlertRules(t *testing.T) {
+	product := Product{MaxPrice: 1000}
+	assert.Equal(t, AlertRules{{Type: RuleTargetPrice, Target: 1000}}, product.AlertRules())
+
+	product.Rules = AlertRules{{Type: RuleAllTimeLow}}
+	assert.Equal(t, AlertRules{{Type: RuleAllTimeLow}}, product.AlertRules())
+
+	require.NoError(t, product.Rules.Scan(`[{"type": "price_drop"}]`))
+	assert.Equal(t, AlertRules{{Type: RuleTargetPrice, Target: 1000}}, product.AlertRules())
+
+	product = Product{}
+	assert.Empty(t, product.AlertRules())
+}
@@ -0,0 +1,38 @@
+package entities
+
+import "strings"
+
+// Availability stock status of a product, as reported by the crawler
+type Availability string
+
+const (
+	// AvailabilityUnknown the crawler did not report the stock status
+	AvailabilityUnknown Availability = ""
+	// AvailabilityInStock the product can be bought
+	AvailabilityInStock Availability = "in_stock"
+	// AvailabilityOutOfStock the product is sold out for now
+	AvailabilityOutOfStock Availability = "out_of_stock"
+	// AvailabilityPreorder the product can be ordered before its release
+	AvailabilityPreorder Availability = "preorder"
+	// AvailabilityUnavailable the product is no longer sold by the store
+	AvailabilityUnavailable Availability = "unavailable"
+	// AvailabilityLimited few units are left
+	AvailabilityLimited Availability = "limited"
+)
+
+// ParseAvailability returns the availability named by value, as written in
+// the crawler output protocol. Unrecognised values are AvailabilityUnknown
+func ParseAvailability(value string) Availability {
+	availability := Availability(strings.ToLower(strings.TrimSpace(value)))
+	switch availability {
+	case AvailabilityInStock, AvailabilityOutOfStock, AvailabilityPreorder, AvailabilityUnavailable, AvailabilityLimited:
+		return availability
+	default:
+		return AvailabilityUnknown
+	}
+}
+
+// IsOutOfStock whether the product can not be bought at the moment
+func (a Availability) IsOutOfStock() bool {
+	return a == AvailabilityOutOfStock || a == AvailabilityUnavailable
+}
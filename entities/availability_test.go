@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAvailability(t *testing.T) {
+	tests := map[string]struct {
+		value          string
+		expectedResult Availability
+	}{
+		"in-stock":     {"in_stock", AvailabilityInStock},
+		"out-of-stock": {"out_of_stock", AvailabilityOutOfStock},
+		"preorder":     {"preorder", AvailabilityPreorder},
+		"unavailable":  {"unavailable", AvailabilityUnavailable},
+		"limited":      {"limited", AvailabilityLimited},
+		"mixed-case":   {" Out_Of_Stock ", AvailabilityOutOfStock},
+		"empty":        {"", AvailabilityUnknown},
+		"unknown":      {"esgotado", AvailabilityUnknown},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expectedResult, ParseAvailability(testData.value))
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package entities
+
+import "errors"
+
+// ErrCrawlerTimeout returned when a crawler exceeds its execution timeout
+var ErrCrawlerTimeout = errors.New("crawler execution timed out")
+
+// ErrDailyBudgetExhausted returned when a crawler already used all the
+// requests it is allowed to make in the day
+var ErrDailyBudgetExhausted = errors.New("daily request budget exhausted")
+
+// ErrUnknownCrawler returned when a product references a crawler name that is
+// not in the registry
+var ErrUnknownCrawler = errors.New("unknown crawler")
+
+// ErrCrawlerDisabled returned when a product references a disabled crawler
+var ErrCrawlerDisabled = errors.New("crawler disabled")
+
+// ErrCircuitOpen returned when a crawler is not run because its circuit
+// breaker is open after too many failures
+var ErrCircuitOpen = errors.New("crawler circuit breaker open")
+
+// ErrUnsupportedStore returned when a product link is not of a store any
+// crawler knows how to check
+var ErrUnsupportedStore = errors.New("unsupported store")
+
+// ErrLinkNotOfCrawler returned when a product link is not of the domains of
+// the crawler the product is assigned to
+var ErrLinkNotOfCrawler = errors.New("link is not of the crawler domains")
@@ -0,0 +1,118 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FailureKind why a crawler could not read a product
+type FailureKind string
+
+const (
+	// FailureBlocked the website refused the request or asked for a captcha
+	FailureBlocked FailureKind = "blocked"
+	// FailureNotFound the product page no longer exists
+	FailureNotFound FailureKind = "not_found"
+	// FailureLayoutChanged the page loaded but the crawler could not find the
+	// product data in it
+	FailureLayoutChanged FailureKind = "layout_changed"
+	// FailureNetwork the website could not be reached
+	FailureNetwork FailureKind = "network"
+	// FailureTimeout the crawler exceeded its execution timeout
+	FailureTimeout FailureKind = "timeout"
+	// FailureParse the crawler output could not be read
+	FailureParse FailureKind = "parse"
+	// FailureUnknown any other failure
+	FailureUnknown FailureKind = "unknown"
+)
+
+var (
+	ErrCrawlerBlocked   = errors.New("crawler blocked by the website")
+	ErrProductNotFound  = errors.New("product not found")
+	ErrLayoutChanged    = errors.New("product page layout changed")
+	ErrCrawlerNetwork   = errors.New("crawler network failure")
+	ErrCrawlerParse     = errors.New("crawler output could not be parsed")
+	ErrCrawlerFailed    = errors.New("crawler failed")
+	failureKindSentinel = map[FailureKind]error{
+		FailureBlocked:       ErrCrawlerBlocked,
+		FailureNotFound:      ErrProductNotFound,
+		FailureLayoutChanged: ErrLayoutChanged,
+		FailureNetwork:       ErrCrawlerNetwork,
+		FailureTimeout:       ErrCrawlerTimeout,
+		FailureParse:         ErrCrawlerParse,
+		FailureUnknown:       ErrCrawlerFailed,
+	}
+)
+
+// ParseFailureKind returns the kind named by code, as used in the error object
+// of the crawler output protocol. "captcha" is read as FailureBlocked and
+// unknown codes map to FailureUnknown
+func ParseFailureKind(code string) FailureKind {
+	if code == "captcha" {
+		return FailureBlocked
+	}
+
+	kind := FailureKind(code)
+	if _, ok := failureKindSentinel[kind]; !ok {
+		return FailureUnknown
+	}
+
+	return kind
+}
+
+// CrawlerError failure of a crawler run. errors.Is matches it against the
+// sentinel of its kind, e.g. ErrProductNotFound for FailureNotFound
+type CrawlerError struct {
+	Kind    FailureKind
+	Message string
+	Err     error
+	// RetryAfter how long the website asked to wait before the next request,
+	// 0 when it did not say
+	RetryAfter time.Duration
+}
+
+func (e *CrawlerError) Error() string {
+	switch {
+	case e.Message != "" && e.Err != nil:
+		return fmt.Sprintf("%s: %s: %v", e.Kind, e.Message, e.Err)
+	case e.Message != "":
+		return fmt.Sprintf("%s: %s", e.Kind, e.Message)
+	case e.Err != nil:
+		return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+	default:
+		return string(e.Kind)
+	}
+}
+
+func (e *CrawlerError) Unwrap() error {
+	return e.Err
+}
+
+func (e *CrawlerError) Is(target error) bool {
+	return failureKindSentinel[e.Kind] == target
+}
+
+// FailureKindOf returns the kind of a crawler failure. Errors that are not a
+// CrawlerError are FailureUnknown, except timeouts
+func FailureKindOf(err error) FailureKind {
+	var crawlerErr *CrawlerError
+	if errors.As(err, &crawlerErr) {
+		return crawlerErr.Kind
+	}
+	if errors.Is(err, ErrCrawlerTimeout) {
+		return FailureTimeout
+	}
+
+	return FailureUnknown
+}
+
+// RetryAfterOf returns how long the website asked to wait after the failure
+func RetryAfterOf(err error) time.Duration {
+	var crawlerErr *CrawlerError
+	if errors.As(err, &crawlerErr) {
+		return crawlerErr.RetryAfter
+	}
+
+	return 0
+}
@@ -0,0 +1,50 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrawlerError(t *testing.T) {
+	err := fmt.Errorf("crawling product: %w", &CrawlerError{Kind: FailureNotFound, Message: "page returned 404"})
+
+	assert.ErrorIs(t, err, ErrProductNotFound)
+	assert.False(t, errors.Is(err, ErrCrawlerBlocked))
+	assert.Equal(t, FailureNotFound, FailureKindOf(err))
+	assert.Equal(t, "crawling product: not_found: page returned 404", err.Error())
+}
+
+func TestFailureKindOf(t *testing.T) {
+	tests := map[string]struct {
+		err          error
+		expectedKind FailureKind
+	}{
+		"crawler-error": {
+			&CrawlerError{Kind: FailureBlocked},
+			FailureBlocked,
+		},
+		"timeout-sentinel": {
+			fmt.Errorf("%w after 1s", ErrCrawlerTimeout),
+			FailureTimeout,
+		},
+		"plain-error": {
+			errors.New("exec: \"pipenv\": executable file not found in $PATH"),
+			FailureUnknown,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expectedKind, FailureKindOf(testData.err))
+		})
+	}
+}
+
+func TestParseFailureKind(t *testing.T) {
+	assert.Equal(t, FailureLayoutChanged, ParseFailureKind("layout_changed"))
+	assert.Equal(t, FailureBlocked, ParseFailureKind("captcha"))
+	assert.Equal(t, FailureUnknown, ParseFailureKind("out_of_memory"))
+}
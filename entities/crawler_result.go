@@ -0,0 +1,13 @@
+package entities
+
+// CrawlerResult product data read from the output of a crawler. Prices are in
+// cents, zero when the page shows none
+type CrawlerResult struct {
+	Price         int
+	OriginalPrice int
+	Discount      string
+	Link          string
+	Currency      string
+	Availability  Availability
+	Seller        string
+}
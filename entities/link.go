@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// NormalizeLink returns the link in a form that is the same for equivalent
+// pastes of a product page: lowercase scheme and host, no fragment, no
+// tracking params, sorted query and no trailing slash. Links that can not be
+// parsed are only trimmed
+func NormalizeLink(link string) string {
+	link = strings.TrimSpace(link)
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Host == "" {
+		return link
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	if (parsed.Scheme == "https" && parsed.Port() == "443") || (parsed.Scheme == "http" && parsed.Port() == "80") {
+		parsed.Host = parsed.Hostname()
+	}
+	parsed.Fragment = ""
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+	parsed.RawPath = ""
+
+	query := parsed.Query()
+	for key := range query {
+		if isTrackingParam(key) {
+			query.Del(key)
+		}
+	}
+	parsed.RawQuery = encodeSortedQuery(query)
+
+	return parsed.String()
+}
+
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	switch key {
+	case "gclid", "fbclid", "ref", "ref_", "tag":
+		return true
+	}
+
+	return strings.HasPrefix(key, "utm_")
+}
+
+func encodeSortedQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	params := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			params = append(params, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+
+	return strings.Join(params, "&")
+}
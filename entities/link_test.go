@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLink(t *testing.T) {
+	tests := map[string]struct {
+		link         string
+		expectedLink string
+	}{
+		"tracking-params": {
+			"https://www.kabum.com.br/produto/123456/?utm_source=google&utm_medium=cpc#reviews",
+			"https://www.kabum.com.br/produto/123456",
+		},
+		"host-case-and-port": {
+			" HTTPS://WWW.Kabum.com.br:443/produto/123456 ",
+			"https://www.kabum.com.br/produto/123456",
+		},
+		"sorted-query": {
+			"https://lista.mercadolivre.com.br/item?b=2&a=1&fbclid=abc",
+			"https://lista.mercadolivre.com.br/item?a=1&b=2",
+		},
+		"not-a-link": {
+			"  not a link ",
+			"not a link",
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expectedLink, NormalizeLink(testData.link))
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Locales prices are written in. pt-BR uses a comma as decimal separator
+// ("R$ 1.299,90"), en-US a dot ("$1,299.90")
+const (
+	LocalePtBR = "pt-BR"
+	LocaleEnUS = "en-US"
+)
+
+// ErrInvalidPrice returned when a text can not be read as a price
+var ErrInvalidPrice = errors.New("invalid price")
+
+// currencySymbols stripped from the start and end of a price, longest first
+var currencySymbols = []string{"US$", "R$", "BRL", "USD", "EUR", "$", "€"}
+
+// ParsePrice reads a price as shown by a store into cents. Currency symbols
+// and codes, spaces and non-breaking spaces are ignored and thousands
+// separators must group digits by three. With an empty locale it is guessed
+// from the currency symbol and then from the separators: the last one is the
+// decimal separator, unless it is followed by three digits. Anything else,
+// including negative amounts and more than two decimals, is an error
+func ParsePrice(text string, locale string) (int, error) {
+	number := strings.TrimFunc(text, unicode.IsSpace)
+	symbol := ""
+	for stripped := true; stripped; {
+		stripped = false
+		for _, s := range currencySymbols {
+			switch {
+			case hasPrefixFold(number, s):
+				number = number[len(s):]
+			case hasSuffixFold(number, s):
+				number = number[:len(number)-len(s)]
+			default:
+				continue
+			}
+			number = strings.TrimFunc(number, unicode.IsSpace)
+			symbol, stripped = s, true
+		}
+	}
+	number = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, number)
+
+	if number == "" {
+		return 0, fmt.Errorf("%w %q: no digits", ErrInvalidPrice, text)
+	}
+	for _, r := range number {
+		if (r < '0' || r > '9') && r != '.' && r != ',' {
+			return 0, fmt.Errorf("%w %q: unexpected %q", ErrInvalidPrice, text, r)
+		}
+	}
+
+	if locale == "" {
+		locale = guessLocale(number, symbol)
+	}
+	decimal, thousands := ",", "."
+	switch locale {
+	case LocalePtBR:
+	case LocaleEnUS:
+		decimal, thousands = ".", ","
+	default:
+		return 0, fmt.Errorf("unsupported price locale %q", locale)
+	}
+
+	units, fraction := number, ""
+	if i := strings.Index(number, decimal); i >= 0 {
+		units, fraction = number[:i], number[i+1:]
+	}
+	if strings.Contains(fraction, decimal) || strings.Contains(fraction, thousands) {
+		return 0, fmt.Errorf("%w %q: misplaced separator", ErrInvalidPrice, text)
+	}
+	if len(fraction) > 2 || (len(fraction) == 0 && strings.Contains(number, decimal)) {
+		return 0, fmt.Errorf("%w %q: expected one or two decimals", ErrInvalidPrice, text)
+	}
+	units, err := removeThousands(units, thousands)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q: %v", ErrInvalidPrice, text, err)
+	}
+	if units == "" && fraction == "" {
+		return 0, fmt.Errorf("%w %q: no digits", ErrInvalidPrice, text)
+	}
+	if len(units) > 15 {
+		return 0, fmt.Errorf("%w %q: too large", ErrInvalidPrice, text)
+	}
+
+	value := 0
+	if units != "" {
+		value, _ = strconv.Atoi(units)
+	}
+	cents, _ := strconv.Atoi((fraction + "00")[:2])
+
+	return value*100 + cents, nil
+}
+
+// guessLocale locale of a price written with the given symbol, going by its
+// separators when the symbol does not tell
+func guessLocale(number string, symbol string) string {
+	switch symbol {
+	case "R$", "BRL", "€", "EUR":
+		return LocalePtBR
+	case "US$", "USD", "$":
+		return LocaleEnUS
+	}
+
+	i := strings.LastIndexAny(number, ".,")
+	if i < 0 {
+		return LocalePtBR
+	}
+
+	decimalIsDot := number[i] == '.'
+	if len(number)-i-1 == 3 && !strings.ContainsAny(number[:i], ".,") {
+		// "1.299" or "1,299": a single separator before three digits groups
+		// thousands
+		decimalIsDot = !decimalIsDot
+	}
+	if decimalIsDot {
+		return LocaleEnUS
+	}
+
+	return LocalePtBR
+}
+
+// removeThousands checks the thousands separators group the digits by three
+// and drops them
+func removeThousands(units string, thousands string) (string, error) {
+	if !strings.Contains(units, thousands) {
+		return units, nil
+	}
+
+	groups := strings.Split(units, thousands)
+	if len(groups[0]) == 0 || len(groups[0]) > 3 {
+		return "", errors.New("misplaced thousands separator")
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return "", errors.New("misplaced thousands separator")
+		}
+	}
+
+	return strings.Join(groups, ""), nil
+}
+
+func hasPrefixFold(s string, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+func hasSuffixFold(s string, suffix string) bool {
+	return len(s) >= len(suffix) && strings.EqualFold(s[len(s)-len(suffix):], suffix)
+}
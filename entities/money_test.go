@@ -0,0 +1,78 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePrice(t *testing.T) {
+	tests := map[string]struct {
+		text          string
+		locale        string
+		expectedPrice int
+	}{
+		"pt-br":                {"R$ 1.299,90", LocalePtBR, 129990},
+		"pt-br-nbsp":           {"R$\u00a01.299,90", LocalePtBR, 129990},
+		"pt-br-narrow-nbsp":    {"1\u202f299,90\u00a0R$", LocalePtBR, 129990},
+		"pt-br-one-decimal":    {"1.299,9", LocalePtBR, 129990},
+		"pt-br-integer":        {"R$ 1.299", LocalePtBR, 129900},
+		"pt-br-millions":       {"R$ 1.234.567,89", LocalePtBR, 123456789},
+		"pt-br-only-cents":     {",99", LocalePtBR, 99},
+		"pt-br-currency-code":  {"1299,90 BRL", LocalePtBR, 129990},
+		"en-us":                {"$1,299.90", LocaleEnUS, 129990},
+		"en-us-plain":          {"1299.90", LocaleEnUS, 129990},
+		"en-us-code":           {"USD 1,299.90", LocaleEnUS, 129990},
+		"space-thousands":      {"1 299,90 €", LocalePtBR, 129990},
+		"guess-real":           {"R$ 1.299", "", 129900},
+		"guess-dollar":         {"US$ 1,299", "", 129900},
+		"guess-dot-decimal":    {"1299.90", "", 129990},
+		"guess-comma-decimal":  {"1299,9", "", 129990},
+		"guess-dot-thousands":  {"1.299", "", 129900},
+		"guess-both-separator": {"1,299.90", "", 129990},
+		"guess-integer":        {"42", "", 4200},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			price, err := ParsePrice(testData.text, testData.locale)
+
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedPrice, price)
+		})
+	}
+}
+
+func TestParsePriceErrors(t *testing.T) {
+	tests := map[string]struct {
+		text   string
+		locale string
+	}{
+		"empty":                {"", ""},
+		"only-symbol":          {"R$ ", ""},
+		"words":                {"Indisponível", LocalePtBR},
+		"installments":         {"10x de R$ 134,99", LocalePtBR},
+		"negative":             {"-10,00", LocalePtBR},
+		"three-decimals":       {"1.299,999", LocalePtBR},
+		"trailing-decimal":     {"1299,", LocalePtBR},
+		"wrong-grouping":       {"12.99,90", LocalePtBR},
+		"two-decimal-commas":   {"1,2,3", LocalePtBR},
+		"locale-mismatch":      {"1,299.90", LocalePtBR},
+		"too-large":            {"9999999999999999999", LocaleEnUS},
+		"only-separator":       {",", LocalePtBR},
+		"unsupported-locale":   {"1.299,90", "de-DE"},
+		"separator-in-decimal": {"1.299,9.0", LocalePtBR},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			_, err := ParsePrice(testData.text, testData.locale)
+
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := ParsePrice("abc", LocalePtBR)
+	assert.ErrorIs(t, err, ErrInvalidPrice)
+}
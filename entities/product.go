@@ -1,13 +1,9 @@
 package entities
 
 import (
-	"errors"
-	"path/filepath"
-	"strings"
+	"time"
 
-	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
 	"github.com/google/uuid"
-	"github.com/oleiade/reflections"
 )
 
 type Product struct {
@@ -17,35 +13,28 @@ type Product struct {
 	MaxPrice    int
 	Link        string
 	CrawlerName string
+	// CheckInterval minutes between checks, already resolved to the crawler
+	// default when the product has none
+	CheckInterval int
+	LastCheckedAt *time.Time
+	// Rules alert rules of the product, a target_price rule on MaxPrice when
+	// none is valid and MaxPrice is set
+	Rules AlertRules `gorm:"type:jsonb"`
 }
 
 func (p *Product) IsBelowMaxPrice(price int) bool {
 	return price != 0 && price <= p.MaxPrice
 }
 
-func (p *Product) GetCrawlerPath(cfg *config.CrawlerConfig) (string, error) {
-	crawlerKey := p.getFormatedCrawlerKey()
-	crawlerPath, err := reflections.GetField(cfg, crawlerKey)
-	if err != nil {
-		return "", err
+// AlertRules rules the checks of the product are evaluated against, none when
+// it has neither valid rules nor a max price
+func (p *Product) AlertRules() AlertRules {
+	if valid := p.Rules.Valid(); len(valid) > 0 {
+		return valid
 	}
-
-	crawlerPathStr, ok := crawlerPath.(string)
-	if !ok {
-		return "", errors.New("invalid crawler path")
-	}
-
-	crawlerAbsPath, err := filepath.Abs(crawlerPathStr)
-	if err != nil {
-		return "", err
+	if p.MaxPrice <= 0 {
+		return nil
 	}
 
-	return crawlerAbsPath, nil
-}
-
-func (p *Product) getFormatedCrawlerKey() string {
-	crawlerKey := strings.Title(p.CrawlerName)
-	crawlerKey = strings.Replace(crawlerKey, "-", "", 1)
-
-	return crawlerKey
+	return AlertRules{{Type: RuleTargetPrice, Target: p.MaxPrice}}
 }
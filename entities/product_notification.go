@@ -1,6 +1,39 @@
 package entities
 
+import "fmt"
+
+// NotificationKind event a queue message is about, sent as its "kind" field
+type NotificationKind string
+
+const (
+	// NotificationBelowMaxPrice the price matched the alert rules of the
+	// product, by default being at or below its max price
+	NotificationBelowMaxPrice NotificationKind = "below_max_price"
+	// NotificationBackInStock the product is in stock again after a check
+	// where it was out of stock
+	NotificationBackInStock NotificationKind = "back_in_stock"
+	// NotificationPriceIncrease the price rose past the configured threshold
+	// since the previous check
+	NotificationPriceIncrease NotificationKind = "price_increase"
+	// NotificationDelisted the product page no longer exists or the store
+	// stopped selling it
+	NotificationDelisted NotificationKind = "delisted"
+)
+
+// ParseNotificationKind returns the opt-in kind named by value.
+// NotificationBelowMaxPrice is always sent, so it is not accepted
+func ParseNotificationKind(value string) (NotificationKind, error) {
+	kind := NotificationKind(value)
+	switch kind {
+	case NotificationBackInStock, NotificationPriceIncrease, NotificationDelisted:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("unknown notification kind %q", value)
+	}
+}
+
 type ProductNotification struct {
+	Kind        NotificationKind `json:"kind"`
 	Description string
 	Price       float64
 	AvgPrice    float64
@@ -8,4 +41,38 @@ type ProductNotification struct {
 	AvgDiscount string
 	Link        string
 	UserID      string
+	// Availability stock status of the check, empty when unknown
+	Availability Availability
+	// MatchedRules alert rules of the product matched by the check
+	MatchedRules AlertRules
+}
+
+// BackInStockNotification payload of NotificationBackInStock
+type BackInStockNotification struct {
+	Kind         NotificationKind `json:"kind"`
+	Description  string
+	Price        float64
+	Availability Availability
+	Link         string
+	UserID       string
+}
+
+// PriceIncreaseNotification payload of NotificationPriceIncrease. Increase is
+// the rise over PreviousPrice as a fraction, e.g. "0.15"
+type PriceIncreaseNotification struct {
+	Kind          NotificationKind `json:"kind"`
+	Description   string
+	Price         float64
+	PreviousPrice float64
+	Increase      string
+	Link          string
+	UserID        string
+}
+
+// DelistedNotification payload of NotificationDelisted
+type DelistedNotification struct {
+	Kind        NotificationKind `json:"kind"`
+	Description string
+	Link        string
+	UserID      string
 }
@@ -13,6 +13,7 @@ type ProductSearchResult struct {
 	Price         int
 	OriginalPrice int
 	Discount      string
+	Availability  Availability
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
@@ -21,6 +22,12 @@ func (p *ProductSearchResult) IsPriceValid() bool {
 	return p.Price > 0
 }
 
+// IsValid results are stored when they carry a price or tell the product is
+// out of stock, in which case stores often show none
+func (p *ProductSearchResult) IsValid() bool {
+	return p.IsPriceValid() || p.Availability.IsOutOfStock()
+}
+
 type Tabler interface {
 	TableName() string
 }
@@ -38,3 +38,23 @@ func TestIsPriceValid(t *testing.T) {
 		})
 	}
 }
+
+func TestIsValid(t *testing.T) {
+	tests := map[string]struct {
+		productSearchResult ProductSearchResult
+		expectedResult      bool
+	}{
+		"price":                  {ProductSearchResult{Price: 1000}, true},
+		"price-out-of-stock":     {ProductSearchResult{Price: 1000, Availability: AvailabilityOutOfStock}, true},
+		"no-price-out-of-stock":  {ProductSearchResult{Availability: AvailabilityOutOfStock}, true},
+		"no-price-unavailable":   {ProductSearchResult{Availability: AvailabilityUnavailable}, true},
+		"no-price-in-stock":      {ProductSearchResult{Availability: AvailabilityInStock}, false},
+		"no-price-unknown-stock": {ProductSearchResult{}, false},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expectedResult, testData.productSearchResult.IsValid())
+		})
+	}
+}
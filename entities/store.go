@@ -0,0 +1,114 @@
+package entities
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// CanonicalLink product link rewritten to the one URL its store uses for the
+// product, along with the store and the product id in it
+type CanonicalLink struct {
+	Store string
+	// StoreID id of the product in the store, empty when the link does not
+	// carry it, as with short links
+	StoreID string
+	URL     string
+}
+
+// store domains of a store and how its product ids are found in a link. Which
+// crawler reads a store is up to the crawler registry
+type store struct {
+	name      string
+	domains   []string
+	canonical func(domain string, link *url.URL) (storeID string, canonicalURL string)
+}
+
+var (
+	amazonASIN        = regexp.MustCompile(`(?i)/(?:dp|gp/product|gp/aw/d|exec/obidos/asin)/([a-z0-9]{10})(?:[/?]|$)`)
+	mercadoLivreItem  = regexp.MustCompile(`(?i)\bMLB-?(\d+)`)
+	mercadoLivreModel = regexp.MustCompile(`(?i)/p/(MLB\d+)`)
+	kabumProduct      = regexp.MustCompile(`^/produto/(\d+)`)
+)
+
+var stores = []store{
+	{
+		name:    "amazon",
+		domains: []string{"amazon.com.br", "amazon.com", "amzn.to", "a.co"},
+		canonical: func(domain string, link *url.URL) (string, string) {
+			match := amazonASIN.FindStringSubmatch(link.Path)
+			if match == nil || domain == "amzn.to" || domain == "a.co" {
+				return "", ""
+			}
+			asin := strings.ToUpper(match[1])
+
+			return asin, "https://www." + domain + "/dp/" + asin
+		},
+	},
+	{
+		name:    "mercado-livre",
+		domains: []string{"mercadolivre.com.br"},
+		canonical: func(domain string, link *url.URL) (string, string) {
+			if match := mercadoLivreModel.FindStringSubmatch(link.Path); match != nil {
+				id := strings.ToUpper(match[1])
+				return id, "https://www.mercadolivre.com.br/p/" + id
+			}
+			if match := mercadoLivreItem.FindStringSubmatch(link.Path); match != nil {
+				return "MLB" + match[1], "https://produto.mercadolivre.com.br/MLB-" + match[1]
+			}
+
+			return "", ""
+		},
+	},
+	{
+		name:    "kabum",
+		domains: []string{"kabum.com.br"},
+		canonical: func(domain string, link *url.URL) (string, string) {
+			match := kabumProduct.FindStringSubmatch(link.Path)
+			if match == nil {
+				return "", ""
+			}
+
+			return match[1], "https://www.kabum.com.br/produto/" + match[1]
+		},
+	},
+}
+
+// CanonicalizeLink finds the store of the link from its domain and rewrites
+// it to the canonical URL of the product, dropping slugs and tracking params.
+// Links of a known store without a product id, as short links, keep their
+// normalized form. Links of any other domain return ErrUnsupportedStore
+func CanonicalizeLink(link string) (CanonicalLink, error) {
+	link = strings.TrimSpace(link)
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Hostname() == "" {
+		return CanonicalLink{}, fmt.Errorf("%w: %q", ErrUnsupportedStore, link)
+	}
+	host := strings.ToLower(parsed.Hostname())
+
+	for _, s := range stores {
+		for _, domain := range s.domains {
+			if !HostInDomain(host, domain) {
+				continue
+			}
+
+			storeID, canonicalURL := s.canonical(domain, parsed)
+			if canonicalURL == "" {
+				canonicalURL = NormalizeLink(link)
+			}
+
+			return CanonicalLink{Store: s.name, StoreID: storeID, URL: canonicalURL}, nil
+		}
+	}
+
+	return CanonicalLink{}, fmt.Errorf("%w: %q", ErrUnsupportedStore, host)
+}
+
+// HostInDomain whether host is domain or one of its subdomains
+func HostInDomain(host, domain string) bool {
+	host = strings.ToLower(host)
+	domain = strings.ToLower(domain)
+
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,61 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalizeLink(t *testing.T) {
+	tests := map[string]struct {
+		link         string
+		expectedLink CanonicalLink
+	}{
+		"amazon-dp": {
+			"https://www.amazon.com.br/Console-PlayStation-5/dp/B0BNSR5G2T/ref=sr_1_1?keywords=ps5&tag=abc-20",
+			CanonicalLink{Store: "amazon", StoreID: "B0BNSR5G2T", URL: "https://www.amazon.com.br/dp/B0BNSR5G2T"},
+		},
+		"amazon-gp-product": {
+			"https://amazon.com.br/gp/product/b0bnsr5g2t?psc=1",
+			CanonicalLink{Store: "amazon", StoreID: "B0BNSR5G2T", URL: "https://www.amazon.com.br/dp/B0BNSR5G2T"},
+		},
+		"amazon-short-link": {
+			"https://amzn.to/3xYzAbC",
+			CanonicalLink{Store: "amazon", URL: "https://amzn.to/3xYzAbC"},
+		},
+		"mercado-livre-item": {
+			"https://produto.mercadolivre.com.br/MLB-1234567890-fone-de-ouvido-bluetooth-_JM#position=1&search_layout=grid",
+			CanonicalLink{Store: "mercado-livre", StoreID: "MLB1234567890", URL: "https://produto.mercadolivre.com.br/MLB-1234567890"},
+		},
+		"mercado-livre-catalog": {
+			"https://www.mercadolivre.com.br/fone-de-ouvido-bluetooth/p/MLB19098287?pdp_filters=category:MLB196208",
+			CanonicalLink{Store: "mercado-livre", StoreID: "MLB19098287", URL: "https://www.mercadolivre.com.br/p/MLB19098287"},
+		},
+		"kabum": {
+			"https://www.kabum.com.br/produto/123456/placa-de-video-rtx?utm_source=google",
+			CanonicalLink{Store: "kabum", StoreID: "123456", URL: "https://www.kabum.com.br/produto/123456"},
+		},
+		"kabum-without-product": {
+			"https://www.kabum.com.br/hardware/?utm_source=google",
+			CanonicalLink{Store: "kabum", URL: "https://www.kabum.com.br/hardware"},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			link, err := CanonicalizeLink(testData.link)
+
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedLink, link)
+		})
+	}
+}
+
+func TestCanonicalizeLinkUnsupportedStore(t *testing.T) {
+	for _, link := range []string{"https://www.notamazon.com.br/dp/B0BNSR5G2T", "https://www.magazineluiza.com.br/produto/123", "not a link", ""} {
+		_, err := CanonicalizeLink(link)
+
+		assert.ErrorIs(t, err, ErrUnsupportedStore, link)
+	}
+}
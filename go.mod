@@ -3,25 +3,21 @@ module github.com/JoaoLeal92/product-monitor-orchestrator
 go 1.16
 
 require (
-	github.com/LyricTian/logrus-mongo-hook v0.0.0-20181228030113-79ee868c8285 // indirect
-	github.com/LyricTian/queue v1.2.0 // indirect
-	github.com/dlclark/regexp2 v1.4.0 // indirect
+	github.com/PuerkitoBio/goquery v1.8.0
 	github.com/fsnotify/fsnotify v1.5.1 // indirect
-	github.com/globalsign/mgo v0.0.0-20181015135952-eeefdecb41b8 // indirect
-	github.com/google/uuid v1.3.0 // indirect
-	github.com/mitchellh/mapstructure v1.4.1 // indirect
-	github.com/oleiade/reflections v1.0.1 // indirect
+	github.com/google/uuid v1.3.0
+	github.com/mitchellh/mapstructure v1.4.1
 	github.com/pelletier/go-toml v1.9.4 // indirect
-	github.com/sirupsen/logrus v1.8.1 // indirect
+	github.com/robfig/cron/v3 v3.0.1
+	github.com/sirupsen/logrus v1.8.1
 	github.com/spf13/cast v1.4.1 // indirect
-	github.com/spf13/viper v1.8.1 // indirect
-	github.com/streadway/amqp v1.0.0 // indirect
-	github.com/stretchr/testify v1.7.1 // indirect
-	github.com/weekface/mgorus v0.0.0-20181029072001-239539fe10e4 // indirect
+	github.com/spf13/viper v1.8.1
+	github.com/streadway/amqp v1.0.0
+	github.com/stretchr/testify v1.7.1
+	github.com/weekface/mgorus v0.0.0-20181029072001-239539fe10e4
 	golang.org/x/sys v0.0.0-20210927094055-39ccf1dd6fa6 // indirect
-	golang.org/x/text v0.3.7 // indirect
 	gopkg.in/ini.v1 v1.63.0 // indirect
 	gopkg.in/mgo.v2 v2.0.0-20190816093944-a6b53ec6cb22 // indirect
-	gorm.io/driver/postgres v1.1.1 // indirect
-	gorm.io/gorm v1.21.15 // indirect
+	gorm.io/driver/postgres v1.1.1
+	gorm.io/gorm v1.21.15
 )
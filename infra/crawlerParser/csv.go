@@ -0,0 +1,49 @@
+package crawlerparser
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+)
+
+// csvColumns column order of the "csv" format. Trailing columns may be left out
+var csvColumns = []string{"price", "original_price", "discount", "link", "currency", "availability", "seller"}
+
+// ErrCSVRowNotFound crawler output is empty
+var ErrCSVRowNotFound = errors.New("no csv row in crawler output")
+
+// parseCSVRow parser of the "csv" format: the last non-empty line of the
+// output is a row with the columns of csvColumns
+func parseCSVRow(crawlerOutput string) (*entities.CrawlerResult, error) {
+	result := &entities.CrawlerResult{}
+
+	lines := strings.Split(strings.TrimSpace(crawlerOutput), "\n")
+	line := strings.TrimSpace(lines[len(lines)-1])
+	if line == "" {
+		return result, &entities.CrawlerError{Kind: entities.FailureParse, Err: ErrCSVRowNotFound}
+	}
+
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.FieldsPerRecord = -1
+	row, err := reader.Read()
+	if err != nil {
+		return result, &entities.CrawlerError{Kind: entities.FailureParse, Err: err}
+	}
+	if len(row) > len(csvColumns) {
+		return result, &entities.CrawlerError{
+			Kind: entities.FailureParse,
+			Err:  fmt.Errorf("csv row has %d columns, expected at most %d", len(row), len(csvColumns)),
+		}
+	}
+
+	for i, value := range row {
+		if err := setField(result, csvColumns[i], strings.TrimSpace(value)); err != nil {
+			return result, &entities.CrawlerError{Kind: entities.FailureParse, Err: err}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,119 @@
+package crawlerparser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/contracts"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+)
+
+// Output formats a crawler entry may declare in output-format
+const (
+	FormatAuto     = "auto"
+	FormatLegacy   = "legacy"
+	FormatJSON     = "json"
+	FormatKeyValue = "kv"
+	FormatCSV      = "csv"
+)
+
+// ErrUnknownFormat no parser registered for the requested output format
+var ErrUnknownFormat = errors.New("unknown crawler output format")
+
+// parserFunc adapts a function to contracts.ResultParser
+type parserFunc func(crawlerOutput string) (*entities.CrawlerResult, error)
+
+func (f parserFunc) ParseCrawlerResult(crawlerOutput string) (*entities.CrawlerResult, error) {
+	return f(crawlerOutput)
+}
+
+// Registry result parsers by output format
+type Registry struct {
+	mu      sync.RWMutex
+	parsers map[string]contracts.ResultParser
+}
+
+// NewRegistry registry holding the built-in formats
+func NewRegistry() *Registry {
+	registry := &Registry{parsers: map[string]contracts.ResultParser{}}
+	registry.Register(FormatAuto, NewResultParser())
+	registry.Register(FormatLegacy, parserFunc(parseLegacy))
+	registry.Register(FormatJSON, parserFunc(parseJSONLine))
+	registry.Register(FormatKeyValue, parserFunc(parseKeyValue))
+	registry.Register(FormatCSV, parserFunc(parseCSVRow))
+
+	return registry
+}
+
+// Register adds or replaces the parser of a format
+func (r *Registry) Register(format string, parser contracts.ResultParser) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.parsers[format] = parser
+}
+
+// Parser returns the parser of a format, where an empty format is auto
+func (r *Registry) Parser(format string) (contracts.ResultParser, error) {
+	if format == "" {
+		format = FormatAuto
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	parser, ok := r.parsers[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+
+	return parser, nil
+}
+
+// setField sets a result field by its protocol name, ignoring unknown ones
+func setField(result *entities.CrawlerResult, name, value string) error {
+	var err error
+	switch name {
+	case "price":
+		if value == "" {
+			return fmt.Errorf("field %s: %w: empty price", name, entities.ErrInvalidPrice)
+		}
+		result.Price, err = priceField(value)
+	case "original_price":
+		result.OriginalPrice, err = priceField(value)
+	case "discount":
+		result.Discount = value
+	case "link":
+		result.Link = value
+	case "currency":
+		result.Currency = value
+	case "availability":
+		result.Availability = entities.ParseAvailability(value)
+	case "seller":
+		result.Seller = value
+	}
+	if err != nil {
+		return fmt.Errorf("field %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// priceField reads a price column, where integers are in cents as in the
+// protocol and other texts are prices as shown by the store. An empty column
+// is no price, which is only allowed for original_price
+func priceField(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if cents, err := strconv.Atoi(value); err == nil {
+		if cents < 0 {
+			return 0, fmt.Errorf("%w: negative price %d", entities.ErrInvalidPrice, cents)
+		}
+		return cents, nil
+	}
+
+	return parsePriceText(value)
+}
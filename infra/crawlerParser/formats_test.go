@@ -0,0 +1,145 @@
+package crawlerparser
+
+import (
+	"testing"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryFormats(t *testing.T) {
+	tests := map[string]struct {
+		format         string
+		crawlerOutput  string
+		expectedResult entities.CrawlerResult
+	}{
+		"default-auto": {
+			"",
+			"Product(price=1000, link='http://test-link.com')",
+			entities.CrawlerResult{Price: 1000, Link: "http://test-link.com"},
+		},
+		"legacy": {
+			FormatLegacy,
+			"carregando\nProduct(price='R$ 1.299,90', original_price=None, link='http://test-link.com')",
+			entities.CrawlerResult{Price: 129990, Link: "http://test-link.com"},
+		},
+		"json": {
+			FormatJSON,
+			`{"schema_version": 1, "price": 1000, "seller": "Loja"}`,
+			entities.CrawlerResult{Price: 1000, Seller: "Loja"},
+		},
+		"kv": {
+			FormatKeyValue,
+			"abrindo página\nprice=1000 original_price=1500 discount='33 %' link=http://test-link.com?a=b",
+			entities.CrawlerResult{Price: 1000, OriginalPrice: 1500, Discount: "33 %", Link: "http://test-link.com?a=b"},
+		},
+		"kv-semicolons": {
+			FormatKeyValue,
+			`price="R$ 1.299,90"; currency=BRL; availability=in_stock; seller="Loja; Filial"`,
+			entities.CrawlerResult{Price: 129990, Currency: "BRL", Availability: "in_stock", Seller: "Loja; Filial"},
+		},
+		"csv": {
+			FormatCSV,
+			"abrindo página\n1000,1500,33%,http://test-link.com\n",
+			entities.CrawlerResult{Price: 1000, OriginalPrice: 1500, Discount: "33%", Link: "http://test-link.com"},
+		},
+		"csv-quoted": {
+			FormatCSV,
+			`"1.299,90",,,http://test-link.com,BRL,in_stock,"Loja, Filial"`,
+			entities.CrawlerResult{Price: 129990, Link: "http://test-link.com", Currency: "BRL", Availability: "in_stock", Seller: "Loja, Filial"},
+		},
+	}
+
+	registry := NewRegistry()
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			parser, err := registry.Parser(testData.format)
+			require.NoError(t, err)
+
+			result, err := parser.ParseCrawlerResult(testData.crawlerOutput)
+
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedResult, *result)
+		})
+	}
+}
+
+func TestRegistryFormatErrors(t *testing.T) {
+	registry := NewRegistry()
+
+	_, err := registry.Parser("xml")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+
+	tests := map[string]struct {
+		format        string
+		crawlerOutput string
+		expectedKind  entities.FailureKind
+	}{
+		"legacy-json-line":  {FormatLegacy, `{"schema_version": 1, "price": 1000}`, entities.FailureParse},
+		"json-legacy-repr":  {FormatJSON, "Product(price=1000)", entities.FailureParse},
+		"kv-without-price":  {FormatKeyValue, "link=http://test-link.com", entities.FailureParse},
+		"kv-unclosed-quote": {FormatKeyValue, "price='1000", entities.FailureParse},
+		"kv-invalid-price":  {FormatKeyValue, "price=abc", entities.FailureParse},
+		"kv-empty-price":    {FormatKeyValue, "price= link=http://test-link.com", entities.FailureParse},
+		"kv-reported-error": {FormatKeyValue, "error=blocked message='captcha page'", entities.FailureBlocked},
+		"csv-empty":         {FormatCSV, "\n\n", entities.FailureParse},
+		"csv-extra-columns": {FormatCSV, "1000,1500,,,,,,extra", entities.FailureParse},
+		"csv-invalid-price": {FormatCSV, `"R$ 12,3,4"`, entities.FailureParse},
+		"csv-empty-price":   {FormatCSV, ",1500,,http://test-link.com", entities.FailureParse},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			parser, err := registry.Parser(testData.format)
+			require.NoError(t, err)
+
+			_, err = parser.ParseCrawlerResult(testData.crawlerOutput)
+
+			assert.Equal(t, testData.expectedKind, entities.FailureKindOf(err))
+		})
+	}
+}
+
+func TestRegistryNegativePrices(t *testing.T) {
+	registry := NewRegistry()
+
+	tests := map[string]struct {
+		format        string
+		crawlerOutput string
+	}{
+		"json-price":          {FormatJSON, `{"schema_version": 1, "price": -500}`},
+		"json-original-price": {FormatJSON, `{"schema_version": 1, "price": 500, "original_price": -1}`},
+		"kv-price":            {FormatKeyValue, "price=-500"},
+		"kv-original-price":   {FormatKeyValue, "price=500 original_price=-1"},
+		"csv-price":           {FormatCSV, "-500"},
+		"csv-original-price":  {FormatCSV, "500,-1"},
+		"legacy-price":        {FormatLegacy, "Product(price=-500)"},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			parser, err := registry.Parser(testData.format)
+			require.NoError(t, err)
+
+			_, err = parser.ParseCrawlerResult(testData.crawlerOutput)
+
+			assert.ErrorIs(t, err, entities.ErrInvalidPrice)
+			assert.Equal(t, entities.FailureParse, entities.FailureKindOf(err))
+		})
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("fixed", parserFunc(func(string) (*entities.CrawlerResult, error) {
+		return &entities.CrawlerResult{Price: 1}, nil
+	}))
+
+	parser, err := registry.Parser("fixed")
+	require.NoError(t, err)
+	result, err := parser.ParseCrawlerResult("")
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Price)
+}
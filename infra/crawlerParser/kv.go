@@ -0,0 +1,102 @@
+package crawlerparser
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+)
+
+// ErrKeyValueNotFound crawler output holds no key=value line with a price or
+// an error
+var ErrKeyValueNotFound = errors.New("no key=value line in crawler output")
+
+// parseKeyValue parser of the "kv" format: the last line of pairs such as
+// price=129990 link="https://..." separated by spaces or ';'. A crawler
+// reports a failure with error=<kind> and an optional message
+func parseKeyValue(crawlerOutput string) (*entities.CrawlerResult, error) {
+	var pairs map[string]string
+
+	scanner := bufio.NewScanner(strings.NewReader(crawlerOutput))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		linePairs, ok := splitKeyValue(scanner.Text())
+		if !ok {
+			continue
+		}
+		_, hasPrice := linePairs["price"]
+		_, hasError := linePairs["error"]
+		if hasPrice || hasError {
+			pairs = linePairs
+		}
+	}
+
+	result := &entities.CrawlerResult{}
+	if pairs == nil {
+		return result, &entities.CrawlerError{Kind: entities.FailureParse, Err: ErrKeyValueNotFound}
+	}
+
+	for name, value := range pairs {
+		if err := setField(result, name, value); err != nil {
+			return result, &entities.CrawlerError{Kind: entities.FailureParse, Err: err}
+		}
+	}
+	if code, ok := pairs["error"]; ok {
+		return result, &entities.CrawlerError{
+			Kind:    entities.ParseFailureKind(code),
+			Message: pairs["message"],
+		}
+	}
+
+	return result, nil
+}
+
+// splitKeyValue reads a line made only of key=value pairs. Values may be
+// quoted with ' or " to hold spaces or ';'
+func splitKeyValue(line string) (map[string]string, bool) {
+	pairs := map[string]string{}
+
+	i := 0
+	for {
+		for i < len(line) && isPairSeparator(line[i]) {
+			i++
+		}
+		if i == len(line) {
+			break
+		}
+
+		eq := strings.IndexByte(line[i:], '=')
+		if eq <= 0 {
+			return nil, false
+		}
+		key := line[i : i+eq]
+		if strings.ContainsAny(key, " \t;\r") {
+			return nil, false
+		}
+		i += eq + 1
+
+		var value string
+		if i < len(line) && (line[i] == '"' || line[i] == '\'') {
+			end := strings.IndexByte(line[i+1:], line[i])
+			if end < 0 {
+				return nil, false
+			}
+			value = line[i+1 : i+1+end]
+			i += end + 2
+		} else {
+			start := i
+			for i < len(line) && !isPairSeparator(line[i]) {
+				i++
+			}
+			value = line[start:i]
+		}
+		pairs[key] = value
+	}
+
+	return pairs, len(pairs) > 0
+}
+
+func isPairSeparator(c byte) bool {
+	return c == ' ' || c == '\t' || c == ';' || c == '\r'
+}
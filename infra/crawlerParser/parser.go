@@ -3,89 +3,122 @@ package crawlerparser
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
-	"github.com/dlclark/regexp2"
-	"github.com/mitchellh/mapstructure"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
 )
 
-type ResultParser struct {
-	reMap map[string]string
-}
+// ResultParser parser of the "auto" format, telling JSON protocol lines from
+// the legacy repr
+type ResultParser struct{}
 
-type crawlerResult struct {
-	Price         int    `mapstructure:"price"`
-	OriginalPrice int    `mapstructure:"originalPrice"`
-	Discount      string `mapstructure:"discount"`
-	Link          string `mapstructure:"link"`
+func NewResultParser() *ResultParser {
+	return &ResultParser{}
 }
 
-func NewResultParser() *ResultParser {
-	reMap := map[string]string{
-		"price":         `(?<=price=).*(?=,\s?original_price)`,
-		"originalPrice": `(?<=original_price=).*(?=,\s?discount)`,
-		"discount":      `(?<=discount=).*(?=,\s?link)`,
-		"link":          `(?<=link=').*(?='\))`,
+// ParseCrawlerResult reads the crawler output, either a JSON protocol line or
+// the legacy Python Product(...) repr. Failures reported by the crawler through
+// the protocol and unreadable outputs are returned as *entities.CrawlerError
+func (r *ResultParser) ParseCrawlerResult(crawlerOutput string) (*entities.CrawlerResult, error) {
+	fmt.Println("Extraindo dados do retorno do crawler")
+	if protocolResult, ok := findProtocolLine(crawlerOutput); ok {
+		return protocolResult.toCrawlerResult()
 	}
 
-	return &ResultParser{
-		reMap: reMap,
-	}
+	return parseLegacy(crawlerOutput)
 }
 
-func (r *ResultParser) ParseCrawlerResult(crawlerOutput string) *crawlerResult {
-	fmt.Println("Extraindo dados do retorno do crawler")
-	crawlerResult, err := r.parseCrawlerOutput(crawlerOutput)
+// parseLegacy parser of the "legacy" format, the Python Product(...) repr
+func parseLegacy(crawlerOutput string) (*entities.CrawlerResult, error) {
+	result, err := parseCrawlerOutput(crawlerOutput)
 	if err != nil {
-		panic(err)
+		return result, &entities.CrawlerError{Kind: entities.FailureParse, Err: err}
 	}
 
-	return crawlerResult
+	return result, nil
 }
 
-func (r *ResultParser) parseCrawlerOutput(out string) (*crawlerResult, error) {
-	crawlerResult := crawlerResult{}
-	parsedData := make(map[string]interface{})
+// parseCrawlerOutput reads the legacy Product(...) repr. Only price is
+// required; unknown fields are ignored
+func parseCrawlerOutput(out string) (*entities.CrawlerResult, error) {
+	result := entities.CrawlerResult{}
+
+	fields, err := parseRepr(out)
+	if err != nil {
+		return &result, err
+	}
+	if _, ok := fields["price"]; !ok {
+		return &result, fmt.Errorf("field price not found in crawler output")
+	}
 
-	for k, v := range r.reMap {
-		re := regexp2.MustCompile(v, 0)
-		m, err := re.FindStringMatch(out)
+	for name, value := range fields {
+		switch name {
+		case "price":
+			result.Price, err = reprPrice(value)
+		case "original_price":
+			result.OriginalPrice, err = reprPrice(value)
+		case "discount":
+			result.Discount, err = reprText(value)
+		case "link":
+			result.Link, err = reprText(value)
+		case "currency":
+			result.Currency, err = reprText(value)
+		case "availability":
+			var availability string
+			availability, err = reprText(value)
+			result.Availability = entities.ParseAvailability(availability)
+		case "seller":
+			result.Seller, err = reprText(value)
+		}
 		if err != nil {
-			fmt.Println(err.Error())
+			return &result, fmt.Errorf("field %s: %w", name, err)
 		}
+	}
 
-		if m.String() == "None" {
-			parsedData[k] = nil
-		} else {
-			if k == "price" || k == "originalPrice" {
-				intPrice, err := r.priceStringToInt(m.String())
-				if err != nil {
-					fmt.Println(err)
-				}
-				parsedData[k] = intPrice
-			} else {
-				parsedData[k] = m.String()
+	return &result, nil
+}
+
+// reprPrice reads a price field. Ints are already in cents, floats are in
+// reais and strings are prices as shown by the store
+func reprPrice(value reprValue) (int, error) {
+	switch {
+	case value.kind == tokenIdent && value.text == "None":
+		return 0, nil
+	case value.kind == tokenNumber:
+		if price, err := strconv.Atoi(value.text); err == nil {
+			if price < 0 {
+				return 0, fmt.Errorf("%w: negative price %d", entities.ErrInvalidPrice, price)
 			}
+			return price, nil
 		}
+		return entities.ParsePrice(value.text, entities.LocaleEnUS)
+	case value.kind == tokenString:
+		return parsePriceText(value.text)
+	default:
+		return 0, fmt.Errorf("%w: %s", entities.ErrInvalidPrice, value.text)
 	}
+}
 
-	err := mapstructure.Decode(parsedData, &crawlerResult)
-	if err != nil {
-		return &crawlerResult, err
+// parsePriceText reads a price written as text, as shown by the store:
+// "1299", "R$ 1.299,90" or "1299.90" are all in reais and read by
+// entities.ParsePrice. Only unquoted ints are already in cents
+func parsePriceText(priceText string) (int, error) {
+	if strings.TrimSpace(priceText) == "" {
+		return 0, fmt.Errorf("%w: empty price", entities.ErrInvalidPrice)
 	}
 
-	return &crawlerResult, nil
+	return entities.ParsePrice(priceText, "")
 }
 
-func (r *ResultParser) priceStringToInt(priceString string) (int, error) {
-	var priceInt int
-	var err error
-
-	if priceString != "" {
-		priceInt, err = strconv.Atoi(priceString)
-		if err != nil {
-			return 0, err
-		}
+// reprText reads a text field, where numbers are kept as written, e.g. a
+// discount of 19
+func reprText(value reprValue) (string, error) {
+	switch {
+	case value.kind == tokenIdent && value.text == "None":
+		return "", nil
+	case value.kind == tokenString || value.kind == tokenNumber:
+		return value.text, nil
+	default:
+		return "", fmt.Errorf("unexpected %s", value.text)
 	}
-
-	return priceInt, nil
 }
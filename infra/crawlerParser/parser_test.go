@@ -0,0 +1,61 @@
+package crawlerparser
+
+import (
+	"testing"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCrawlerResult(t *testing.T) {
+	tests := map[string]struct {
+		crawlerOutput  string
+		expectedResult entities.CrawlerResult
+	}{
+		"legacy-repr": {
+			"Loading .env environment variables...\nProduct(price=1000, original_price=1500, discount=None, link='http://test-link.com')",
+			entities.CrawlerResult{Price: 1000, OriginalPrice: 1500, Link: "http://test-link.com"},
+		},
+		"legacy-repr-formatted-price": {
+			"Product(price='R$ 1.299,90', original_price=1599.90, discount=None, link='http://test-link.com')",
+			entities.CrawlerResult{Price: 129990, OriginalPrice: 159990, Link: "http://test-link.com"},
+		},
+		"json-line": {
+			`{"schema_version": 1, "price": 1000, "original_price": 1500, "discount": "33%", "currency": "BRL", "availability": "in_stock", "link": "http://test-link.com"}`,
+			entities.CrawlerResult{Price: 1000, OriginalPrice: 1500, Discount: "33%", Currency: "BRL", Availability: "in_stock", Link: "http://test-link.com"},
+		},
+		"json-line-after-logs": {
+			"starting browser\n{\"debug\": true}\n{\"schema_version\": 1, \"price\": 1000, \"original_price\": null}\n",
+			entities.CrawlerResult{Price: 1000},
+		},
+	}
+
+	parser := NewResultParser()
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			result, err := parser.ParseCrawlerResult(testData.crawlerOutput)
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedResult, *result)
+		})
+	}
+}
+
+func TestParseCrawlerResultErrors(t *testing.T) {
+	parser := NewResultParser()
+
+	_, err := parser.ParseCrawlerResult(`{"schema_version": 1, "price": null, "error": {"code": "not_found", "message": "page returned 404"}}`)
+	assert.ErrorIs(t, err, entities.ErrProductNotFound)
+	assert.Equal(t, "not_found: page returned 404", err.Error())
+
+	_, err = parser.ParseCrawlerResult(`{"schema_version": 2, "price": 1000}`)
+	assert.ErrorIs(t, err, ErrUnsupportedSchemaVersion)
+	assert.ErrorIs(t, err, entities.ErrCrawlerParse)
+
+	_, err = parser.ParseCrawlerResult("Traceback (most recent call last):")
+	assert.ErrorIs(t, err, entities.ErrCrawlerParse)
+
+	_, err = parser.ParseCrawlerResult("Product(price='Indisponível', original_price=None, discount=None, link='http://test-link.com')")
+	assert.ErrorIs(t, err, entities.ErrCrawlerParse)
+	assert.ErrorIs(t, err, entities.ErrInvalidPrice)
+}
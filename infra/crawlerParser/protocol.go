@@ -0,0 +1,114 @@
+package crawlerparser
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+)
+
+// ProtocolSchemaVersion latest version of the JSON line protocol understood by
+// the orchestrator
+const ProtocolSchemaVersion = 1
+
+// ErrUnsupportedSchemaVersion crawler output uses a protocol version newer than
+// ProtocolSchemaVersion
+var ErrUnsupportedSchemaVersion = errors.New("unsupported crawler protocol schema version")
+
+// ErrProtocolLineNotFound crawler output holds no JSON protocol line
+var ErrProtocolLineNotFound = errors.New("no protocol line in crawler output")
+
+// ProtocolResult JSON line a crawler prints on stdout, see docs/crawler-protocol.md.
+// Prices are in cents, the same unit as products.max_price
+type ProtocolResult struct {
+	SchemaVersion int            `json:"schema_version"`
+	Price         *int           `json:"price"`
+	OriginalPrice *int           `json:"original_price"`
+	Discount      string         `json:"discount,omitempty"`
+	Currency      string         `json:"currency,omitempty"`
+	Availability  string         `json:"availability,omitempty"`
+	Seller        string         `json:"seller,omitempty"`
+	Link          string         `json:"link,omitempty"`
+	Error         *ProtocolError `json:"error,omitempty"`
+}
+
+// ProtocolError failure reported by the crawler itself. Code is one of the
+// entities.FailureKind values
+type ProtocolError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// findProtocolLine returns the last line of the output holding a JSON object
+// with a schema version, so logs printed before the result are ignored
+func findProtocolLine(out string) (*ProtocolResult, bool) {
+	var found *ProtocolResult
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		result := ProtocolResult{}
+		if err := json.Unmarshal([]byte(line), &result); err != nil || result.SchemaVersion == 0 {
+			continue
+		}
+		found = &result
+	}
+
+	return found, found != nil
+}
+
+// parseJSONLine parser of the "json" format, the protocol line alone
+func parseJSONLine(crawlerOutput string) (*entities.CrawlerResult, error) {
+	protocolResult, ok := findProtocolLine(crawlerOutput)
+	if !ok {
+		return &entities.CrawlerResult{}, &entities.CrawlerError{Kind: entities.FailureParse, Err: ErrProtocolLineNotFound}
+	}
+
+	return protocolResult.toCrawlerResult()
+}
+
+func (p *ProtocolResult) toCrawlerResult() (*entities.CrawlerResult, error) {
+	if p.SchemaVersion > ProtocolSchemaVersion {
+		return nil, &entities.CrawlerError{
+			Kind: entities.FailureParse,
+			Err:  fmt.Errorf("%w: %d", ErrUnsupportedSchemaVersion, p.SchemaVersion),
+		}
+	}
+
+	if (p.Price != nil && *p.Price < 0) || (p.OriginalPrice != nil && *p.OriginalPrice < 0) {
+		return nil, &entities.CrawlerError{
+			Kind: entities.FailureParse,
+			Err:  fmt.Errorf("%w: negative price", entities.ErrInvalidPrice),
+		}
+	}
+
+	result := &entities.CrawlerResult{
+		Discount:     p.Discount,
+		Currency:     p.Currency,
+		Availability: entities.ParseAvailability(p.Availability),
+		Seller:       p.Seller,
+		Link:         p.Link,
+	}
+	if p.Price != nil {
+		result.Price = *p.Price
+	}
+	if p.OriginalPrice != nil {
+		result.OriginalPrice = *p.OriginalPrice
+	}
+	if p.Error != nil {
+		return result, &entities.CrawlerError{
+			Kind:    entities.ParseFailureKind(p.Error.Code),
+			Message: p.Error.Message,
+		}
+	}
+
+	return result, nil
+}
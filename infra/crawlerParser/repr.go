@@ -0,0 +1,287 @@
+package crawlerparser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrReprNotFound crawler output holds no Product(...) repr
+var ErrReprNotFound = errors.New("no Product(...) repr in crawler output")
+
+// ReprError Product(...) repr that could not be read, with the byte offset in
+// the repr where reading stopped
+type ReprError struct {
+	Offset  int
+	Message string
+}
+
+func (e *ReprError) Error() string {
+	return fmt.Sprintf("invalid Product repr at offset %d: %s", e.Offset, e.Message)
+}
+
+type reprTokenKind int
+
+const (
+	tokenEOF reprTokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenLParen
+	tokenRParen
+	tokenEquals
+	tokenComma
+)
+
+func (k reprTokenKind) String() string {
+	return [...]string{"end of repr", "name", "string", "number", "'('", "')'", "'='", "','"}[k]
+}
+
+type reprToken struct {
+	kind   reprTokenKind
+	text   string
+	offset int
+}
+
+// reprValue value of a Product field, kept as written for the numbers so
+// prices can tell cents from reais
+type reprValue struct {
+	kind reprTokenKind
+	text string
+}
+
+// reprLexer splits a Python repr in tokens. Strings are returned unquoted and
+// unescaped
+type reprLexer struct {
+	input string
+	pos   int
+}
+
+// parseRepr reads the last Product(...) repr of the output, made of keyword
+// fields in any order whose values are Python literals: quoted strings, ints,
+// floats, None, True and False. Text after the closing parenthesis is ignored.
+// The output is scanned from the start, skipping the reprs read, so a
+// "Product(" inside one of their strings is not taken for another repr. A
+// "Product(" that can not be read, e.g. in a log line, only fails the parse
+// when no repr before it could be read
+func parseRepr(out string) (map[string]reprValue, error) {
+	var fields map[string]reprValue
+	err := ErrReprNotFound
+	for pos := 0; ; {
+		start := strings.Index(out[pos:], "Product(")
+		if start < 0 {
+			return fields, err
+		}
+		start += pos
+
+		parsed, end, parseErr := parseReprAt(out[start:])
+		if parseErr != nil {
+			if fields == nil {
+				err = parseErr
+			}
+			pos = start + len("Product(")
+			continue
+		}
+		fields, err = parsed, nil
+		pos = start + end
+	}
+}
+
+// parseReprAt reads the repr input starts with, returning the offset following
+// its closing parenthesis
+func parseReprAt(input string) (map[string]reprValue, int, error) {
+	lexer := &reprLexer{input: input, pos: len("Product")}
+	if _, err := lexer.expect(tokenLParen); err != nil {
+		return nil, 0, err
+	}
+
+	fields := make(map[string]reprValue)
+	for {
+		token, err := lexer.next()
+		if err != nil {
+			return nil, 0, err
+		}
+		if token.kind == tokenRParen {
+			return fields, lexer.pos, nil
+		}
+		if token.kind != tokenIdent {
+			return nil, 0, lexer.unexpected(token, tokenIdent)
+		}
+		name := token.text
+
+		if _, err := lexer.expect(tokenEquals); err != nil {
+			return nil, 0, err
+		}
+		value, err := lexer.next()
+		if err != nil {
+			return nil, 0, err
+		}
+		switch value.kind {
+		case tokenString, tokenNumber, tokenIdent:
+		default:
+			return nil, 0, lexer.unexpected(value, tokenString)
+		}
+		if _, ok := fields[name]; ok {
+			return nil, 0, &ReprError{Offset: token.offset, Message: fmt.Sprintf("field %s repeated", name)}
+		}
+		fields[name] = reprValue{kind: value.kind, text: value.text}
+
+		separator, err := lexer.next()
+		if err != nil {
+			return nil, 0, err
+		}
+		switch separator.kind {
+		case tokenComma:
+		case tokenRParen:
+			return fields, lexer.pos, nil
+		default:
+			return nil, 0, lexer.unexpected(separator, tokenComma)
+		}
+	}
+}
+
+func (l *reprLexer) expect(kind reprTokenKind) (reprToken, error) {
+	token, err := l.next()
+	if err != nil {
+		return token, err
+	}
+	if token.kind != kind {
+		return token, l.unexpected(token, kind)
+	}
+
+	return token, nil
+}
+
+func (l *reprLexer) unexpected(token reprToken, expected reprTokenKind) error {
+	return &ReprError{Offset: token.offset, Message: fmt.Sprintf("expected %s, found %s", expected, token.kind)}
+}
+
+func (l *reprLexer) next() (reprToken, error) {
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.pos += size
+	}
+	if l.pos >= len(l.input) {
+		return reprToken{kind: tokenEOF, offset: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.input[l.pos]
+	switch {
+	case c == '(':
+		l.pos++
+		return reprToken{kind: tokenLParen, text: "(", offset: start}, nil
+	case c == ')':
+		l.pos++
+		return reprToken{kind: tokenRParen, text: ")", offset: start}, nil
+	case c == '=':
+		l.pos++
+		return reprToken{kind: tokenEquals, text: "=", offset: start}, nil
+	case c == ',':
+		l.pos++
+		return reprToken{kind: tokenComma, text: ",", offset: start}, nil
+	case c == '\'' || c == '"':
+		return l.string(c)
+	case c == '-' || c == '+' || c == '.' || isDigit(c):
+		return l.number()
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.input) && (l.input[l.pos] == '_' || isLetter(l.input[l.pos]) || isDigit(l.input[l.pos])) {
+			l.pos++
+		}
+		return reprToken{kind: tokenIdent, text: l.input[start:l.pos], offset: start}, nil
+	default:
+		r, _ := utf8.DecodeRuneInString(l.input[l.pos:])
+		return reprToken{}, &ReprError{Offset: start, Message: fmt.Sprintf("unexpected %q", r)}
+	}
+}
+
+// string reads a quoted string, resolving the escapes Python uses in reprs
+func (l *reprLexer) string(quote byte) (reprToken, error) {
+	start := l.pos
+	l.pos++
+
+	var text strings.Builder
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		switch {
+		case c == quote:
+			l.pos++
+			return reprToken{kind: tokenString, text: text.String(), offset: start}, nil
+		case c == '\n':
+			return reprToken{}, &ReprError{Offset: l.pos, Message: "unterminated string"}
+		case c != '\\':
+			text.WriteByte(c)
+			l.pos++
+			continue
+		}
+
+		if l.pos+1 >= len(l.input) {
+			break
+		}
+		escape := l.input[l.pos+1]
+		l.pos += 2
+		switch escape {
+		case '\\', '\'', '"':
+			text.WriteByte(escape)
+		case 'n':
+			text.WriteByte('\n')
+		case 't':
+			text.WriteByte('\t')
+		case 'r':
+			text.WriteByte('\r')
+		case 'x', 'u', 'U':
+			digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[escape]
+			if l.pos+digits > len(l.input) {
+				return reprToken{}, &ReprError{Offset: l.pos - 2, Message: "truncated escape"}
+			}
+			code, err := strconv.ParseUint(l.input[l.pos:l.pos+digits], 16, 32)
+			if err != nil || code > unicode.MaxRune {
+				return reprToken{}, &ReprError{Offset: l.pos - 2, Message: fmt.Sprintf("invalid escape \\%c%s", escape, l.input[l.pos:l.pos+digits])}
+			}
+			text.WriteRune(rune(code))
+			l.pos += digits
+		default:
+			// Python keeps unknown escapes as written
+			text.WriteByte('\\')
+			text.WriteByte(escape)
+		}
+	}
+
+	return reprToken{}, &ReprError{Offset: start, Message: "unterminated string"}
+}
+
+// number reads an int or float literal, validated by strconv
+func (l *reprLexer) number() (reprToken, error) {
+	start := l.pos
+	if c := l.input[l.pos]; c == '-' || c == '+' {
+		l.pos++
+	}
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		if !isDigit(c) && c != '.' && c != '_' && c != 'e' && c != 'E' && !((c == '-' || c == '+') && (l.input[l.pos-1] == 'e' || l.input[l.pos-1] == 'E')) {
+			break
+		}
+		l.pos++
+	}
+
+	text := strings.ReplaceAll(l.input[start:l.pos], "_", "")
+	if _, err := strconv.ParseFloat(text, 64); err != nil {
+		return reprToken{}, &ReprError{Offset: start, Message: fmt.Sprintf("invalid number %q", l.input[start:l.pos])}
+	}
+
+	return reprToken{kind: tokenNumber, text: text, offset: start}, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
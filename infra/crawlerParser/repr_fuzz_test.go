@@ -0,0 +1,39 @@
+//go:build go1.18
+// +build go1.18
+
+package crawlerparser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+)
+
+func FuzzParseCrawlerResult(f *testing.F) {
+	seeds := []string{
+		"Product(price=1000, original_price=1500, discount=None, link='http://test-link.com')",
+		`Product(price='R$ 1.299,90', discount="10%", link='http://a.com/\')\x41\u00e9')`,
+		"Product(price=1_299.9e1, original_price=-1, extra=True,)",
+		"Product(price=",
+		"Product('",
+		`{"schema_version": 1, "price": 1000}`,
+		"",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	parser := NewResultParser()
+	f.Fuzz(func(t *testing.T, out string) {
+		result, err := parser.ParseCrawlerResult(out)
+		if result == nil && err == nil {
+			t.Fatal("no result and no error")
+		}
+
+		var crawlerErr *entities.CrawlerError
+		if err != nil && !errors.As(err, &crawlerErr) {
+			t.Fatalf("error %v is not a CrawlerError", err)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package crawlerparser
+
+import (
+	"testing"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLegacyRepr(t *testing.T) {
+	tests := map[string]struct {
+		crawlerOutput  string
+		expectedResult entities.CrawlerResult
+	}{
+		"any-field-order": {
+			"Product(link='http://test-link.com', discount=None, original_price=1500, price=1000)",
+			entities.CrawlerResult{Price: 1000, OriginalPrice: 1500, Link: "http://test-link.com"},
+		},
+		"quoted-discount": {
+			`Product(price=1000, original_price=1500, discount='33%', link="http://test-link.com")`,
+			entities.CrawlerResult{Price: 1000, OriginalPrice: 1500, Discount: "33%", Link: "http://test-link.com"},
+		},
+		"link-with-quote-and-paren": {
+			`Product(price=1000, original_price=None, discount=None, link='http://test-link.com/a,b?q=\')x\'')`,
+			entities.CrawlerResult{Price: 1000, Link: "http://test-link.com/a,b?q=')x'"},
+		},
+		"escapes": {
+			`Product(price=1000, discount='10\u00a0%', link="http://test-link.com/\"x\"\\y\x41")`,
+			entities.CrawlerResult{Price: 1000, Discount: "10\u00a0%", Link: `http://test-link.com/"x"\yA`},
+		},
+		"float-prices": {
+			"Product(price=1299.9, original_price=1_599.90, discount=19, link='http://test-link.com')",
+			entities.CrawlerResult{Price: 129990, OriginalPrice: 159990, Discount: "19", Link: "http://test-link.com"},
+		},
+		"quoted-prices-are-store-text": {
+			"Product(price='1299', original_price='R$ 1299')",
+			entities.CrawlerResult{Price: 129900, OriginalPrice: 129900},
+		},
+		"extra-fields": {
+			"Product(price=1000, seller='Loja', currency='BRL', availability='in_stock', rating=4.5, prime=True)",
+			entities.CrawlerResult{Price: 1000, Seller: "Loja", Currency: "BRL", Availability: "in_stock"},
+		},
+		"trailing-comma-and-spaces": {
+			"Product(\n  price = 1000 ,\n  link = 'http://test-link.com',\n)",
+			entities.CrawlerResult{Price: 1000, Link: "http://test-link.com"},
+		},
+		"last-repr-after-logs": {
+			"Product(price=1, link='old')\nProduct(price=2, link='new') done",
+			entities.CrawlerResult{Price: 2, Link: "new"},
+		},
+		"log-line-after-repr": {
+			"Product(price=1000, link='x')\nDone. Product(s) processed: 1",
+			entities.CrawlerResult{Price: 1000, Link: "x"},
+		},
+		"repr-inside-string": {
+			"Product(price=3, seller='Product(price=1)', link='new')",
+			entities.CrawlerResult{Price: 3, Seller: "Product(price=1)", Link: "new"},
+		},
+	}
+
+	parser := NewResultParser()
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			result, err := parser.ParseCrawlerResult(testData.crawlerOutput)
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedResult, *result)
+		})
+	}
+}
+
+func TestParseLegacyReprErrors(t *testing.T) {
+	tests := map[string]struct {
+		crawlerOutput  string
+		expectedOffset int
+	}{
+		"unterminated-string": {"Product(price=1000, link='http://test-link.com)", 25},
+		"missing-equals":      {"Product(price 1000)", 14},
+		"missing-comma":       {"Product(price=1000 link='x')", 19},
+		"unclosed":            {"Product(price=1000,", 19},
+		"invalid-number":      {"Product(price=12.3.4)", 14},
+		"invalid-escape":      {`Product(price=1000, link='\xzz')`, 26},
+		"positional-value":    {"Product(1000)", 8},
+		"repeated-field":      {"Product(price=1000, price=2000)", 20},
+	}
+
+	parser := NewResultParser()
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			_, err := parser.ParseCrawlerResult(testData.crawlerOutput)
+
+			assert.ErrorIs(t, err, entities.ErrCrawlerParse)
+			var reprErr *ReprError
+			require.ErrorAs(t, err, &reprErr)
+			assert.Equal(t, testData.expectedOffset, reprErr.Offset)
+		})
+	}
+
+	_, err := parser.ParseCrawlerResult("Traceback (most recent call last):")
+	assert.ErrorIs(t, err, ErrReprNotFound)
+
+	_, err = parser.ParseCrawlerResult("Product(link='http://test-link.com')")
+	assert.ErrorIs(t, err, entities.ErrCrawlerParse)
+
+	_, err = parser.ParseCrawlerResult("Product(price='R$ 12,3,4')")
+	assert.ErrorIs(t, err, entities.ErrInvalidPrice)
+
+	_, err = parser.ParseCrawlerResult("Product(price=-1000)")
+	assert.ErrorIs(t, err, entities.ErrInvalidPrice)
+
+	_, err = parser.ParseCrawlerResult("Product(price='')")
+	assert.ErrorIs(t, err, entities.ErrInvalidPrice)
+
+	_, err = parser.ParseCrawlerResult("Product(price=1000, link=None, discount=True)")
+	assert.ErrorIs(t, err, entities.ErrCrawlerParse)
+}
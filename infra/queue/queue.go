@@ -1,6 +1,7 @@
 package queue
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
@@ -36,7 +37,13 @@ func NewQueueManager(cfg *config.QueueConfig) (*QueueManager, error) {
 	}, nil
 }
 
-func (q *QueueManager) SendMessage(message interface{}) error {
+// SendMessage publishes the message unless ctx is already done. The amqp
+// client has no cancellable publish, so ctx is only checked beforehand
+func (q *QueueManager) SendMessage(ctx context.Context, message interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	jsonMessage, err := json.Marshal(message)
 	if err != nil {
 		return err
@@ -1,59 +1,124 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/contracts"
 	"github.com/JoaoLeal92/product-monitor-orchestrator/crawler"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/crawler/native"
 	"github.com/JoaoLeal92/product-monitor-orchestrator/data"
 	crawlerparser "github.com/JoaoLeal92/product-monitor-orchestrator/infra/crawlerParser"
 	"github.com/JoaoLeal92/product-monitor-orchestrator/infra/logs"
 	"github.com/JoaoLeal92/product-monitor-orchestrator/infra/queue"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/scheduler"
 	"github.com/JoaoLeal92/product-monitor-orchestrator/services"
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run starts the orchestrator and returns its exit code. It returns instead of
+// calling os.Exit, so the deferred cleanups (persistent crawler workers,
+// queue connection) always run
+func run() int {
 	fmt.Println("Start orchestrator")
 	cfg, err := config.ReadConfig()
 	if err != nil {
 		fmt.Printf("Error: %v", err)
-		os.Exit(1)
+		return 1
 	}
 
 	logger := logs.NewLogger(&cfg.Log)
 	db, _ := data.Instance(cfg.Db)
-	parser := crawlerparser.NewResultParser()
+	parser := crawlerparser.NewRegistry()
 
 	queueManager, err := queue.NewQueueManager(&cfg.Queue)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Erro ao conectar-se com o gerenciador de filas: %v", err))
-		os.Exit(1)
+		return 1
 	}
 	defer queueManager.CloseConnection()
 	defer queueManager.CloseChannel()
 
-	products, err := db.Products().GetProductsListForCrawler()
+	registry, err := crawler.NewRegistry(&cfg.Crawlers, parser)
 	if err != nil {
-		fmt.Printf("Error: %v", err)
-		os.Exit(1)
+		logger.Error(fmt.Sprintf("Erro na configuração dos crawlers: %v", err))
+		return 1
+	}
+
+	productNotificationService, err := services.NewProductNotificationService(&cfg.Notifications, db, logger, queueManager)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Erro na configuração das notificações: %v", err))
+		return 1
+	}
+	execCrawler := crawler.NewCrawler(&cfg.Crawlers, logger)
+	defer execCrawler.Close()
+	nativeCrawler := native.NewCrawler(&cfg.Crawlers, logger)
+	if err := nativeCrawler.CheckSites(cfg.Crawlers.Sites); err != nil {
+		logger.Error(fmt.Sprintf("Erro na configuração dos crawlers: %v", err))
+		return 1
+	}
+	crawlerService := services.NewCrawlerService(parser, &cfg.Crawlers, db, productNotificationService, logger, crawler.NewRouter(execCrawler, nativeCrawler), registry)
+
+	// SIGINT/SIGTERM stop new products from being crawled; in-flight ones get
+	// up to crawlers.shutdown-timeout to finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		sched, err := scheduler.NewScheduler(&cfg.Scheduler, logger)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Erro na configuração do agendador: %v", err))
+			return 1
+		}
+
+		logger.Info("Iniciando orquestrador em modo serviço")
+		sched.Run(ctx, func(ctx context.Context) {
+			if err := runCrawler(ctx, &cfg, db, crawlerService, logger); err != nil {
+				logger.Error(fmt.Sprintf("Erro na execução do crawler: %v", err))
+			}
+		})
+		return 0
 	}
 
-	productNotificationService := services.NewProductNotificationService(db, logger, queueManager)
-	crawler := crawler.NewCrawler(&cfg.Crawlers, logger)
-	crawlerService := services.NewCrawlerService(parser, &cfg.Crawlers, productNotificationService, logger, crawler)
+	if err := runCrawler(ctx, &cfg, db, crawlerService, logger); err != nil {
+		logger.Error(fmt.Sprintf("Erro na execução do crawler: %v", err))
+		return 1
+	}
+
+	return 0
+}
 
+func runCrawler(ctx context.Context, cfg *config.Config, db contracts.RepoManager, crawlerService *services.CrawlerService, logger contracts.LoggerContract) error {
 	startTime := time.Now()
-	err = crawlerService.Execute(products)
-	elapsedTime := time.Since(startTime)
-	if err != nil {
-		panic(err)
+	if cfg.Crawlers.Lease.Enabled {
+		if err := crawlerService.ExecuteWithLeases(ctx); err != nil {
+			return err
+		}
+	} else {
+		products, err := db.Products().GetDueProductsForCrawler(ctx, cfg.Crawlers.DefaultCheckInterval)
+		if err != nil {
+			return err
+		}
+
+		if err := crawlerService.Execute(ctx, products); err != nil {
+			return err
+		}
 	}
+	elapsedTime := time.Since(startTime)
 
 	logger.ClearField("user_id")
 	logger.Info("Produtos processados com sucesso")
 	logger.Info(fmt.Sprintf("Tempo de execução do crawler: %v", elapsedTime))
+	logger.Info(fmt.Sprintf("Estatísticas da execução: %s", crawlerService.Stats()))
 
 	logger.Info("Fim da operação do crawler")
+	return nil
 }
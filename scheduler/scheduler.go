@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/contracts"
+)
+
+// Scheduler triggers a job following a cron expression or a fixed interval
+type Scheduler struct {
+	schedule   cron.Schedule
+	runOnStart bool
+	logger     contracts.LoggerContract
+	now        func() time.Time
+	after      func(d time.Duration) <-chan time.Time
+}
+
+// NewScheduler instantiates a new scheduler from the scheduler configs
+func NewScheduler(cfg *config.SchedulerConfig, logger contracts.LoggerContract) (*Scheduler, error) {
+	schedule, err := parseSchedule(cfg)
+	if err != nil {
+		return &Scheduler{}, err
+	}
+
+	return &Scheduler{
+		schedule:   schedule,
+		runOnStart: cfg.RunOnStart,
+		logger:     logger,
+		now:        time.Now,
+		after:      time.After,
+	}, nil
+}
+
+func parseSchedule(cfg *config.SchedulerConfig) (cron.Schedule, error) {
+	if cfg.Cron != "" {
+		schedule, err := cron.ParseStandard(cfg.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", cfg.Cron, err)
+		}
+		return schedule, nil
+	}
+
+	if cfg.Interval > 0 {
+		return cron.Every(cfg.Interval), nil
+	}
+
+	return nil, errors.New("scheduler requires a cron expression or an interval")
+}
+
+// Run blocks until ctx is done, running job on every scheduled time. Runs
+// never overlap: the next run is scheduled only after the current one finishes
+func (s *Scheduler) Run(ctx context.Context, job func(ctx context.Context)) {
+	if s.runOnStart {
+		job(ctx)
+	}
+
+	for ctx.Err() == nil {
+		now := s.now()
+		next := s.schedule.Next(now)
+		s.logger.Info(fmt.Sprintf("Próxima execução agendada para %s", next.Format(time.RFC3339)))
+
+		select {
+		case <-ctx.Done():
+		case <-s.after(next.Sub(now)):
+			job(ctx)
+		}
+	}
+
+	s.logger.Info("Agendador encerrado")
+}
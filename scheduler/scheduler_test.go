@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	mocks "github.com/JoaoLeal92/product-monitor-orchestrator/contracts/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerRun(t *testing.T) {
+	tests := map[string]struct {
+		cfg            config.SchedulerConfig
+		stopAfter      int
+		expectedDelays []time.Duration
+	}{
+		"interval": {
+			config.SchedulerConfig{Interval: 10 * time.Minute},
+			2,
+			[]time.Duration{10 * time.Minute, 10 * time.Minute, 10 * time.Minute},
+		},
+		"cron": {
+			config.SchedulerConfig{Cron: "0 * * * *"},
+			2,
+			[]time.Duration{40 * time.Minute, time.Hour, time.Hour},
+		},
+		"cron-over-interval": {
+			config.SchedulerConfig{Cron: "*/15 * * * *", Interval: time.Hour},
+			1,
+			[]time.Duration{10 * time.Minute, 15 * time.Minute},
+		},
+		"run-on-start": {
+			config.SchedulerConfig{Interval: 10 * time.Minute, RunOnStart: true},
+			2,
+			[]time.Duration{10 * time.Minute, 10 * time.Minute},
+		},
+		"cancelled-while-waiting": {
+			config.SchedulerConfig{Interval: 10 * time.Minute},
+			0,
+			[]time.Duration{10 * time.Minute},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mockLogger := mocks.NewLoggerContract(t)
+			mockLogger.On("Info", mock.Anything).Return(nil)
+
+			scheduler, err := NewScheduler(&testData.cfg, mockLogger)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			now := time.Date(2022, 5, 10, 12, 20, 0, 0, time.UTC)
+			runs := 0
+			delays := []time.Duration{}
+			scheduler.now = func() time.Time { return now }
+			scheduler.after = func(d time.Duration) <-chan time.Time {
+				delays = append(delays, d)
+				if runs >= testData.stopAfter {
+					cancel()
+					return make(chan time.Time)
+				}
+
+				now = now.Add(d)
+				fired := make(chan time.Time, 1)
+				fired <- now
+				return fired
+			}
+
+			scheduler.Run(ctx, func(ctx context.Context) { runs++ })
+
+			assert.Equal(t, testData.stopAfter, runs)
+			assert.Equal(t, testData.expectedDelays, delays)
+		})
+	}
+}
+
+func TestNewSchedulerErrors(t *testing.T) {
+	tests := map[string]config.SchedulerConfig{
+		"no-schedule":  {},
+		"invalid-cron": {Cron: "every hour", Interval: time.Hour},
+	}
+
+	for testName, cfg := range tests {
+		t.Run(testName, func(t *testing.T) {
+			_, err := NewScheduler(&cfg, nil)
+			assert.Error(t, err)
+		})
+	}
+}
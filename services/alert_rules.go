@@ -0,0 +1,111 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/google/uuid"
+)
+
+// needsHistory whether any of the rules compares the check with the previous
+// ones
+func needsHistory(rules entities.AlertRules) bool {
+	for _, rule := range rules {
+		if rule.Type != entities.RuleTargetPrice && rule.Type != entities.RuleNearTarget {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchRules returns the rules matched by the price of the check. The rules
+// found invalid when the product was read are logged and skipped
+func (p *ProductNotificationService) matchRules(product entities.Product, current entities.ProductSearchResult, productSearchHistory []entities.ProductSearchResult) entities.AlertRules {
+	for _, err := range product.Rules.Errors() {
+		p.logger.Error(fmt.Sprintf("%s: Regra de alerta inválida: %v", product.ID, err))
+	}
+	previousPrices := pricedHistory(productSearchHistory, current)
+
+	matched := entities.AlertRules{}
+	for _, rule := range product.AlertRules() {
+		if ruleMatches(rule, current.Price, previousPrices) {
+			matched = append(matched, rule)
+		}
+	}
+
+	return matched
+}
+
+// ruleMatches evaluates a rule against the price of the check and the prices
+// of the previous checks, oldest first
+func ruleMatches(rule entities.AlertRule, price int, previousPrices []int) bool {
+	if price <= 0 {
+		return false
+	}
+
+	switch rule.Type {
+	case entities.RuleTargetPrice:
+		return price <= rule.Target
+	case entities.RuleNearTarget:
+		return float64(price) <= float64(rule.Target)*(1+rule.Percent/100)
+	case entities.RuleBelowAverage:
+		window := previousPrices
+		if rule.Window > 0 && len(window) > rule.Window {
+			window = window[len(window)-rule.Window:]
+		}
+		if len(window) == 0 {
+			return false
+		}
+		sum := 0
+		for _, previous := range window {
+			sum += previous
+		}
+		avg := float64(sum) / float64(len(window))
+		return float64(price) <= avg*(1-rule.Percent/100)
+	case entities.RuleAllTimeLow:
+		if len(previousPrices) == 0 {
+			return false
+		}
+		for _, previous := range previousPrices {
+			if price >= previous {
+				return false
+			}
+		}
+		return true
+	case entities.RuleDropSinceLastCheck:
+		if len(previousPrices) == 0 {
+			return false
+		}
+		last := previousPrices[len(previousPrices)-1]
+		drop := float64(last-price) / float64(last)
+		return price < last && drop*100 >= rule.Percent
+	default:
+		return false
+	}
+}
+
+// pricedHistory prices of the checks before the current one, oldest first,
+// leaving out the checks without a price
+func pricedHistory(productSearchHistory []entities.ProductSearchResult, current entities.ProductSearchResult) []int {
+	checks := make([]entities.ProductSearchResult, 0, len(productSearchHistory))
+	for _, check := range productSearchHistory {
+		if current.ID != uuid.Nil && check.ID == current.ID {
+			continue
+		}
+		if check.IsPriceValid() {
+			checks = append(checks, check)
+		}
+	}
+	sort.SliceStable(checks, func(i, j int) bool {
+		return checks[i].CreatedAt.Before(checks[j].CreatedAt)
+	})
+
+	prices := make([]int, 0, len(checks))
+	for _, check := range checks {
+		prices = append(prices, check.Price)
+	}
+
+	return prices
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	mocks "github.com/JoaoLeal92/product-monitor-orchestrator/contracts/mocks"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleMatches(t *testing.T) {
+	tests := map[string]struct {
+		rule           entities.AlertRule
+		price          int
+		previousPrices []int
+		expectedResult bool
+	}{
+		"target-price":                  {entities.AlertRule{Type: entities.RuleTargetPrice, Target: 1000}, 1000, nil, true},
+		"above-target-price":            {entities.AlertRule{Type: entities.RuleTargetPrice, Target: 1000}, 1001, nil, false},
+		"near-target":                   {entities.AlertRule{Type: entities.RuleNearTarget, Target: 1000, Percent: 5}, 1050, nil, true},
+		"not-near-target":               {entities.AlertRule{Type: entities.RuleNearTarget, Target: 1000, Percent: 5}, 1051, nil, false},
+		"below-average":                 {entities.AlertRule{Type: entities.RuleBelowAverage, Percent: 10}, 900, []int{1000, 1000}, true},
+		"not-below-average":             {entities.AlertRule{Type: entities.RuleBelowAverage, Percent: 10}, 901, []int{1000, 1000}, false},
+		"below-rolling-average":         {entities.AlertRule{Type: entities.RuleBelowAverage, Percent: 10, Window: 2}, 900, []int{2000, 1000, 1000}, true},
+		"below-average-without-history": {entities.AlertRule{Type: entities.RuleBelowAverage, Percent: 10}, 900, nil, false},
+		"all-time-low":                  {entities.AlertRule{Type: entities.RuleAllTimeLow}, 899, []int{1000, 900, 950}, true},
+		"not-all-time-low":              {entities.AlertRule{Type: entities.RuleAllTimeLow}, 900, []int{1000, 900, 950}, false},
+		"all-time-low-without-history":  {entities.AlertRule{Type: entities.RuleAllTimeLow}, 900, nil, false},
+		"drop-since-last-check":         {entities.AlertRule{Type: entities.RuleDropSinceLastCheck, Percent: 20}, 800, []int{500, 1000}, true},
+		"small-drop-since-last-check":   {entities.AlertRule{Type: entities.RuleDropSinceLastCheck, Percent: 20}, 801, []int{500, 1000}, false},
+		"any-drop-since-last-check":     {entities.AlertRule{Type: entities.RuleDropSinceLastCheck}, 999, []int{1000}, true},
+		"same-price-since-last-check":   {entities.AlertRule{Type: entities.RuleDropSinceLastCheck}, 1000, []int{1000}, false},
+		"no-price":                      {entities.AlertRule{Type: entities.RuleTargetPrice, Target: 1000}, 0, nil, false},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expectedResult, ruleMatches(testData.rule, testData.price, testData.previousPrices))
+		})
+	}
+}
+
+func TestPricedHistory(t *testing.T) {
+	checkedAt := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	history := []entities.ProductSearchResult{
+		{Price: 1200, CreatedAt: checkedAt.Add(-time.Hour)},
+		{Availability: entities.AvailabilityOutOfStock, CreatedAt: checkedAt.Add(-2 * time.Hour)},
+		{Price: 1000, CreatedAt: checkedAt.Add(-3 * time.Hour)},
+	}
+
+	assert.Equal(t, []int{1000, 1200}, pricedHistory(history, entities.ProductSearchResult{Price: 900}))
+}
+
+func TestProductNotificationServiceRules(t *testing.T) {
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockProductSearcHistoryRepo := mocks.NewProductSearchHistoryRepository(t)
+	mockQueueManager := mocks.NewQueueManager(t)
+	mockLogger := mocks.NewLoggerContract(t)
+
+	mockRepoManager.On("ProductSearchHistory").Return(mockProductSearcHistoryRepo)
+	mockProductSearcHistoryRepo.On("InsertNewHistory", mock.Anything, mock.Anything).Return(nil)
+	mockProductSearcHistoryRepo.On("GetHistoryByProductID", mock.Anything, mock.Anything).Return([]entities.ProductSearchResult{
+		{Price: 1500},
+		{Price: 1400},
+	}, nil).Once()
+	mockLogger.On("Error", mock.Anything).Return(nil)
+	mockQueueManager.On("SendMessage", mock.Anything, mock.MatchedBy(func(notification entities.ProductNotification) bool {
+		return assert.ObjectsAreEqual(entities.AlertRules{
+			{Type: entities.RuleAllTimeLow},
+			{Type: entities.RuleDropSinceLastCheck, Percent: 5},
+		}, notification.MatchedRules)
+	})).Return(nil)
+
+	productNotificationService, err := NewProductNotificationService(&config.NotificationConfig{}, mockRepoManager, mockLogger, mockQueueManager)
+	require.NoError(t, err)
+	product := entities.Product{
+		Description: "test-product",
+		MaxPrice:    1000,
+	}
+	err = product.Rules.Scan(`[
+		{"type": "target_price", "target": 1000},
+		{"type": "all_time_low"},
+		{"type": "drop_since_last_check", "percent": 5},
+		{"type": "below_average", "percent": 20},
+		{"type": "price_drop"}
+	]`)
+	require.NoError(t, err)
+	err = productNotificationService.Execute(context.Background(), &product, &entities.ProductSearchResult{Price: 1300})
+
+	require.NoError(t, err)
+	mockQueueManager.AssertNumberOfCalls(t, "SendMessage", 1)
+	mockLogger.AssertNumberOfCalls(t, "Error", 1)
+}
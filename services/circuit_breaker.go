@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/contracts"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+)
+
+const (
+	defaultBreakerMinRequests = 10
+	defaultBreakerCoolDown    = 5 * time.Minute
+)
+
+type breakerState string
+
+const (
+	breakerClosed   breakerState = "fechado"
+	breakerOpen     breakerState = "aberto"
+	breakerHalfOpen breakerState = "meio aberto"
+)
+
+// circuitBreakers circuit breaker of each crawler, keyed by the crawler name.
+// They outlive a run, so a site that was failing stays skipped through the
+// cool-down in the next scheduled runs too
+type circuitBreakers struct {
+	mu       sync.Mutex
+	defaults config.CircuitBreakerConfig
+	logger   contracts.LoggerContract
+	now      func() time.Time
+	breakers map[string]*circuitBreaker
+}
+
+// circuitBreaker keeps the outcome of the last MinRequests requests of a
+// crawler in a ring buffer
+type circuitBreaker struct {
+	name         string
+	cfg          config.CircuitBreakerConfig
+	state        breakerState
+	outcomes     []bool
+	next         int
+	openedAt     time.Time
+	trialRunning bool
+}
+
+func newCircuitBreakers(defaults config.CircuitBreakerConfig, logger contracts.LoggerContract) *circuitBreakers {
+	return &circuitBreakers{
+		defaults: defaults,
+		logger:   logger,
+		now:      time.Now,
+		breakers: make(map[string]*circuitBreaker),
+	}
+}
+
+// allow returns ErrCircuitOpen when the crawler should not be run. Otherwise
+// the returned function must be called with the outcome of the run
+func (c *circuitBreakers) allow(site config.CrawlerSiteConfig) (func(err error), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	breaker := c.breaker(site)
+	if breaker == nil {
+		return func(error) {}, nil
+	}
+
+	switch breaker.state {
+	case breakerOpen:
+		if c.now().Sub(breaker.openedAt) < breaker.cfg.CoolDown {
+			return nil, fmt.Errorf("%w: %q", entities.ErrCircuitOpen, site.Name)
+		}
+		c.transition(breaker, breakerHalfOpen)
+		fallthrough
+	case breakerHalfOpen:
+		if breaker.trialRunning {
+			return nil, fmt.Errorf("%w: %q", entities.ErrCircuitOpen, site.Name)
+		}
+		breaker.trialRunning = true
+		return func(err error) { c.recordTrial(breaker, err) }, nil
+	default:
+		return func(err error) { c.record(breaker, err) }, nil
+	}
+}
+
+func (c *circuitBreakers) breaker(site config.CrawlerSiteConfig) *circuitBreaker {
+	breaker, ok := c.breakers[site.Name]
+	if ok {
+		return breaker
+	}
+
+	cfg := c.defaults
+	if site.CircuitBreaker.FailureRatio > 0 {
+		cfg.FailureRatio = site.CircuitBreaker.FailureRatio
+	}
+	if site.CircuitBreaker.MinRequests > 0 {
+		cfg.MinRequests = site.CircuitBreaker.MinRequests
+	}
+	if site.CircuitBreaker.CoolDown > 0 {
+		cfg.CoolDown = site.CircuitBreaker.CoolDown
+	}
+	if cfg.FailureRatio <= 0 {
+		c.breakers[site.Name] = nil
+		return nil
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = defaultBreakerMinRequests
+	}
+	if cfg.CoolDown <= 0 {
+		cfg.CoolDown = defaultBreakerCoolDown
+	}
+
+	breaker = &circuitBreaker{
+		name:  site.Name,
+		cfg:   cfg,
+		state: breakerClosed,
+	}
+	c.breakers[site.Name] = breaker
+
+	return breaker
+}
+
+func (c *circuitBreakers) record(breaker *circuitBreaker, err error) {
+	if !countsForBreaker(err) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if breaker.state != breakerClosed {
+		return
+	}
+
+	if len(breaker.outcomes) < breaker.cfg.MinRequests {
+		breaker.outcomes = append(breaker.outcomes, err != nil)
+	} else {
+		breaker.outcomes[breaker.next] = err != nil
+		breaker.next = (breaker.next + 1) % breaker.cfg.MinRequests
+	}
+
+	failures := 0
+	for _, failed := range breaker.outcomes {
+		if failed {
+			failures++
+		}
+	}
+	if len(breaker.outcomes) >= breaker.cfg.MinRequests && float64(failures)/float64(len(breaker.outcomes)) >= breaker.cfg.FailureRatio {
+		c.logger.Warn(fmt.Sprintf("%d falhas nas últimas %d requisições do crawler %s", failures, len(breaker.outcomes), breaker.name))
+		c.open(breaker)
+	}
+}
+
+func (c *circuitBreakers) recordTrial(breaker *circuitBreaker, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	breaker.trialRunning = false
+	if !countsForBreaker(err) {
+		return
+	}
+
+	if err != nil {
+		c.open(breaker)
+		return
+	}
+
+	breaker.outcomes = nil
+	breaker.next = 0
+	c.transition(breaker, breakerClosed)
+}
+
+func (c *circuitBreakers) open(breaker *circuitBreaker) {
+	breaker.openedAt = c.now()
+	c.transition(breaker, breakerOpen)
+}
+
+func (c *circuitBreakers) transition(breaker *circuitBreaker, state breakerState) {
+	breaker.state = state
+	c.logger.Warn(fmt.Sprintf("Circuito do crawler %s %s", breaker.name, state))
+}
+
+// countsForBreaker whether the outcome says something about the health of the
+// site. Missing products, skipped requests, links not of the crawler and
+// interrupted runs do not
+func countsForBreaker(err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, context.Canceled), errors.Is(err, entities.ErrDailyBudgetExhausted), errors.Is(err, entities.ErrCircuitOpen), errors.Is(err, entities.ErrLinkNotOfCrawler):
+		return false
+	default:
+		return entities.FailureKindOf(err) != entities.FailureNotFound
+	}
+}
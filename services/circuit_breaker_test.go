@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	mocks "github.com/JoaoLeal92/product-monitor-orchestrator/contracts/mocks"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockLogger.On("Warn", mock.Anything).Return(nil)
+
+	now := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+	breakers := newCircuitBreakers(config.CircuitBreakerConfig{FailureRatio: 0.5, MinRequests: 4, CoolDown: time.Minute}, mockLogger)
+	breakers.now = func() time.Time { return now }
+	site := config.CrawlerSiteConfig{Name: "amazon"}
+	blocked := &entities.CrawlerError{Kind: entities.FailureBlocked}
+
+	outcomes := []error{nil, blocked, &entities.CrawlerError{Kind: entities.FailureNotFound}, nil, blocked}
+	for _, outcome := range outcomes {
+		recordOutcome, err := breakers.allow(site)
+		require.NoError(t, err)
+		recordOutcome(outcome)
+	}
+
+	_, err := breakers.allow(site)
+	assert.ErrorIs(t, err, entities.ErrCircuitOpen, "2 failures in the last 4 counted requests")
+	mockLogger.AssertCalled(t, "Warn", "Circuito do crawler amazon aberto")
+
+	now = now.Add(time.Minute)
+	recordTrial, err := breakers.allow(site)
+	require.NoError(t, err, "cool-down is over, trial request allowed")
+	_, err = breakers.allow(site)
+	assert.ErrorIs(t, err, entities.ErrCircuitOpen, "a single trial at a time")
+
+	recordTrial(blocked)
+	_, err = breakers.allow(site)
+	assert.ErrorIs(t, err, entities.ErrCircuitOpen, "failed trial opens the circuit again")
+
+	now = now.Add(time.Minute)
+	recordTrial, err = breakers.allow(site)
+	require.NoError(t, err)
+	recordTrial(nil)
+	mockLogger.AssertCalled(t, "Warn", "Circuito do crawler amazon fechado")
+
+	recordOutcome, err := breakers.allow(site)
+	require.NoError(t, err)
+	recordOutcome(blocked)
+	_, err = breakers.allow(site)
+	assert.NoError(t, err, "counts start over once closed")
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	breakers := newCircuitBreakers(config.CircuitBreakerConfig{}, mockLogger)
+	site := config.CrawlerSiteConfig{Name: "amazon"}
+
+	for i := 0; i < 20; i++ {
+		recordOutcome, err := breakers.allow(site)
+		require.NoError(t, err)
+		recordOutcome(&entities.CrawlerError{Kind: entities.FailureBlocked})
+	}
+}
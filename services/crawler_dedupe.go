@@ -0,0 +1,122 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/google/uuid"
+)
+
+// watchers product rows sharing a link, keyed by the id of the row that is
+// crawled for all of them. Rows crawled alone with their link as stored are
+// not in it
+type watchers map[uuid.UUID][]entities.Product
+
+// of returns every row the crawled product stands for, itself included
+func (w watchers) of(product entities.Product) []entities.Product {
+	if rows, ok := w[product.ID]; ok {
+		return rows
+	}
+
+	return []entities.Product{product}
+}
+
+// resolveStores fills in the crawler of products without one from the domain
+// of their link, falling back to FallbackCrawler for unsupported stores, and
+// checks the link of the others is of the domains of their crawler. Products
+// left without a crawler or with a link of another store are failed and
+// dropped
+func (c *CrawlerService) resolveStores(products []entities.Product) []entities.Product {
+	resolved := make([]entities.Product, 0, len(products))
+	for _, product := range products {
+		if product.CrawlerName == "" {
+			crawlerName, err := c.registry.CrawlerForLink(product.Link)
+			switch {
+			case err == nil:
+				product.CrawlerName = crawlerName
+			case c.cfg.FallbackCrawler != "" && product.Link != "":
+				product.CrawlerName = c.cfg.FallbackCrawler
+			default:
+				c.logCrawlerError(product, err)
+				continue
+			}
+		} else if err := c.checkLink(product); err != nil {
+			c.logCrawlerError(product, err)
+			continue
+		}
+		resolved = append(resolved, product)
+	}
+
+	return resolved
+}
+
+// checkLink fails products whose crawler declares domains when their link is
+// not in them. Unknown crawlers are left for the crawl to report
+func (c *CrawlerService) checkLink(product entities.Product) error {
+	site, err := c.registry.Site(product.CrawlerName)
+	if err != nil || len(site.Domains) == 0 {
+		return nil
+	}
+
+	crawlerName, err := c.registry.CrawlerForLink(product.Link)
+	if err != nil || crawlerName != product.CrawlerName {
+		return fmt.Errorf("%w: %q is not a link of crawler %q", entities.ErrLinkNotOfCrawler, product.Link, product.CrawlerName)
+	}
+
+	return nil
+}
+
+// dedupeByLink keeps one product per crawler and link, so a page watched by
+// several users is crawled once per run. Links of a known store read by the
+// crawler declaring their domain are compared and crawled in their canonical
+// form, others only normalized
+func dedupeByLink(products []entities.Product, crawlerForLink func(link string) (string, error)) ([]entities.Product, watchers) {
+	type linkKey struct {
+		crawlerName string
+		link        string
+	}
+
+	firstByLink := make(map[linkKey]int)
+	unique := make([]entities.Product, 0, len(products))
+	shared := make(watchers)
+	for _, product := range products {
+		crawled := product
+		link := entities.NormalizeLink(product.Link)
+		canonical, err := entities.CanonicalizeLink(product.Link)
+		if err == nil && ownsLink(crawlerForLink, product) {
+			crawled.Link = canonical.URL
+			link = canonical.URL
+		}
+		if link == "" {
+			unique = append(unique, product)
+			continue
+		}
+
+		key := linkKey{crawlerName: product.CrawlerName, link: link}
+		i, ok := firstByLink[key]
+		if !ok {
+			firstByLink[key] = len(unique)
+			unique = append(unique, crawled)
+			if crawled.Link != product.Link {
+				shared[crawled.ID] = []entities.Product{product}
+			}
+			continue
+		}
+
+		first := unique[i]
+		if _, ok := shared[first.ID]; !ok {
+			shared[first.ID] = []entities.Product{first}
+		}
+		shared[first.ID] = append(shared[first.ID], product)
+	}
+
+	return unique, shared
+}
+
+// ownsLink whether the crawler of the product is the one declaring the domain
+// of its link
+func ownsLink(crawlerForLink func(link string) (string, error), product entities.Product) bool {
+	crawlerName, err := crawlerForLink(product.Link)
+
+	return err == nil && crawlerName == product.CrawlerName
+}
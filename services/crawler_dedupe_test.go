@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupeByLink(t *testing.T) {
+	products := []entities.Product{
+		{ID: uuid.New(), CrawlerName: "kabum", Link: "https://www.kabum.com.br/produto/123456"},
+		{ID: uuid.New(), CrawlerName: "amazon", Link: "https://www.amazon.com.br/dp/B000000000"},
+		{ID: uuid.New(), CrawlerName: "kabum", Link: "https://www.kabum.com.br/produto/123456/?utm_source=telegram"},
+		{ID: uuid.New(), CrawlerName: "kabum"},
+		{ID: uuid.New(), CrawlerName: "kabum"},
+	}
+
+	unique, productWatchers := dedupeByLink(products, storeCrawlers)
+
+	assert.Equal(t, []entities.Product{products[0], products[1], products[3], products[4]}, unique)
+	assert.Equal(t, []entities.Product{products[0], products[2]}, productWatchers.of(products[0]))
+	assert.Equal(t, []entities.Product{products[1]}, productWatchers.of(products[1]))
+}
+
+func TestDedupeByLinkCanonicalLinks(t *testing.T) {
+	products := []entities.Product{
+		{ID: uuid.New(), CrawlerName: "amazon", Link: "https://www.amazon.com.br/Console-PlayStation-5/dp/B0BNSR5G2T/ref=sr_1_1"},
+		{ID: uuid.New(), CrawlerName: "amazon", Link: "https://amazon.com.br/gp/product/B0BNSR5G2T?psc=1"},
+		{ID: uuid.New(), CrawlerName: "custom", Link: "https://www.kabum.com.br/produto/123456/placa-de-video"},
+	}
+
+	unique, productWatchers := dedupeByLink(products, storeCrawlers)
+
+	require.Len(t, unique, 2)
+	assert.Equal(t, "https://www.amazon.com.br/dp/B0BNSR5G2T", unique[0].Link)
+	assert.Equal(t, []entities.Product{products[0], products[1]}, productWatchers.of(unique[0]))
+	assert.Equal(t, products[2], unique[1], "links are only canonicalized for the crawler of their store")
+}
+
+// storeCrawlers reads every known store with the crawler of the same name
+func storeCrawlers(link string) (string, error) {
+	canonical, err := entities.CanonicalizeLink(link)
+
+	return canonical.Store, err
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/google/uuid"
+)
+
+// collectedProducts products set aside by the jobs of a run, such as the ones
+// whose page no longer exists, handled once the run is over
+type collectedProducts struct {
+	mu       sync.Mutex
+	products []entities.Product
+}
+
+func (d *collectedProducts) add(product entities.Product) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.products = append(d.products, product)
+}
+
+func (d *collectedProducts) take() []entities.Product {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	products := d.products
+	d.products = nil
+
+	return products
+}
+
+func (d *collectedProducts) reset() {
+	d.take()
+}
+
+// storeDelisted stores an unavailable check for every watcher of the products
+// not found by their crawler, so they are kept in the history and may raise a
+// delisted notification
+func (c *CrawlerService) storeDelisted(ctx context.Context, productWatchers watchers, checkedProducts *[]uuid.UUID) {
+	for _, job := range c.delisted.take() {
+		c.logger.Info(fmt.Sprintf("%s Produto %s não encontrado, registrado como indisponível", job.ID, job.Description))
+		for _, product := range productWatchers.of(job) {
+			productSearchResult := entities.ProductSearchResult{
+				ProductID:    product.ID,
+				UserID:       product.UserID,
+				Availability: entities.AvailabilityUnavailable,
+			}
+
+			if c.storeResult(ctx, product, &productSearchResult) {
+				*checkedProducts = append(*checkedProducts, product.ID)
+			}
+		}
+	}
+}
+
+// markFailedChecked marks every watcher of the products that failed for good
+// as checked, so they wait for their check interval instead of being crawled
+// and failing again on every run
+func (c *CrawlerService) markFailedChecked(ctx context.Context, productWatchers watchers, checkedProducts *[]uuid.UUID) {
+	for _, job := range c.failed.take() {
+		for _, product := range productWatchers.of(job) {
+			if c.markChecked(ctx, product) {
+				*checkedProducts = append(*checkedProducts, product.ID)
+			}
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+)
+
+// crawlerPool bounded pool of a single crawler: the products waiting for it and
+// how many runs of it are going on. Each run crawls up to batchSize products.
+// waiting counts the runs waiting to take their slot back
+type crawlerPool struct {
+	name      string
+	limit     int
+	batchSize int
+	active    int
+	waiting   int
+	pending   []entities.Product
+}
+
+// dispatcher hands products to the crawler pools in round robin, so a slow
+// crawler does not hold back the others, while keeping the total of running
+// crawlers under a global cap. Runs give their slot back while they wait for
+// the rate limits or a retry backoff, and take it back before new runs start
+type dispatcher struct {
+	mu      sync.Mutex
+	pools   []*crawlerPool
+	next    int
+	active  int
+	waiting int
+	limit   int
+	freed   chan struct{}
+}
+
+func newDispatcher(products []entities.Product, globalLimit int, crawlerLimit func(crawlerName string) int, crawlerBatchSize func(crawlerName string) int) *dispatcher {
+	poolsByName := make(map[string]*crawlerPool)
+	pools := []*crawlerPool{}
+	for _, product := range products {
+		pool, ok := poolsByName[product.CrawlerName]
+		if !ok {
+			pool = &crawlerPool{
+				name:      product.CrawlerName,
+				limit:     atLeastOne(crawlerLimit(product.CrawlerName)),
+				batchSize: atLeastOne(crawlerBatchSize(product.CrawlerName)),
+			}
+			poolsByName[product.CrawlerName] = pool
+			pools = append(pools, pool)
+		}
+		pool.pending = append(pool.pending, product)
+	}
+
+	return &dispatcher{
+		pools: pools,
+		limit: atLeastOne(globalLimit),
+		freed: make(chan struct{}),
+	}
+}
+
+// nextJob takes the next batch of products from the first pool, in round
+// robin order, that has pending products and a free slot
+func (d *dispatcher) nextJob() (*crawlerPool, []entities.Product, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.active+d.waiting >= d.limit {
+		return nil, nil, false
+	}
+
+	for i := 0; i < len(d.pools); i++ {
+		pool := d.pools[(d.next+i)%len(d.pools)]
+		if len(pool.pending) == 0 || pool.active+pool.waiting >= pool.limit {
+			continue
+		}
+
+		d.next = (d.next + i + 1) % len(d.pools)
+		size := pool.batchSize
+		if size > len(pool.pending) {
+			size = len(pool.pending)
+		}
+		job := pool.pending[:size:size]
+		pool.pending = pool.pending[size:]
+		pool.active++
+		d.active++
+
+		return pool, job, true
+	}
+
+	return nil, nil, false
+}
+
+func (d *dispatcher) release(pool *crawlerPool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	pool.active--
+	d.active--
+	close(d.freed)
+	d.freed = make(chan struct{})
+}
+
+// acquire takes a slot of the pool back, blocking until one is free or ctx is
+// done. Runs taking their slot back go before the new ones
+func (d *dispatcher) acquire(ctx context.Context, pool *crawlerPool) error {
+	d.mu.Lock()
+	pool.waiting++
+	d.waiting++
+	defer func() {
+		pool.waiting--
+		d.waiting--
+		d.mu.Unlock()
+	}()
+
+	for d.active >= d.limit || pool.active >= pool.limit {
+		freed := d.freed
+		d.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			d.mu.Lock()
+			return ctx.Err()
+		case <-freed:
+		}
+		d.mu.Lock()
+	}
+	pool.active++
+	d.active++
+
+	return nil
+}
+
+// slotFreed returns a channel closed on the next release of a slot
+func (d *dispatcher) slotFreed() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.freed
+}
+
+func (d *dispatcher) hasPending() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, pool := range d.pools {
+		if len(pool.pending) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dispatchCrawlerJobs crawls the products through the per crawler pools, in
+// batches for crawlers with a batch size, and blocks until every started job
+// is over. Cancelling ctx stops new jobs from being started; running ones use
+// runCtx
+func (c *CrawlerService) dispatchCrawlerJobs(ctx context.Context, runCtx context.Context, products []entities.Product, processingChannels processingChannels) {
+	defer close(processingChannels.CrawlerResultsChan)
+
+	d := newDispatcher(products, c.cfg.NumCrawlers, c.crawlerConcurrency, c.crawlerBatchSize)
+	finished := make(chan struct{})
+	stopFeeding := ctx.Done()
+	running := 0
+
+	for {
+		freed := d.slotFreed()
+		for ctx.Err() == nil {
+			pool, job, ok := d.nextJob()
+			if !ok {
+				break
+			}
+
+			running++
+			go func(slot *jobSlot, job []entities.Product) {
+				if len(job) == 1 {
+					c.crawlProduct(runCtx, job[0], slot, processingChannels)
+				} else {
+					c.crawlBatch(runCtx, job, slot, processingChannels)
+				}
+				slot.done()
+				finished <- struct{}{}
+			}(&jobSlot{dispatcher: d, pool: pool, held: true}, job)
+		}
+
+		if running == 0 && (ctx.Err() != nil || !d.hasPending()) {
+			return
+		}
+
+		select {
+		case <-finished:
+			running--
+		case <-freed:
+		case <-stopFeeding:
+			stopFeeding = nil
+		}
+	}
+}
+
+// jobSlot slot of a running job in the dispatcher
+type jobSlot struct {
+	dispatcher *dispatcher
+	pool       *crawlerPool
+	held       bool
+}
+
+// pause gives the slot back for delay, so waiting for the rate limits or a
+// retry backoff does not hold back the other jobs, and takes it back after
+func (s *jobSlot) pause(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	s.done()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	}
+
+	if err := s.dispatcher.acquire(ctx, s.pool); err != nil {
+		return err
+	}
+	s.held = true
+
+	return nil
+}
+
+// done gives the slot back for good
+func (s *jobSlot) done() {
+	if s.held {
+		s.dispatcher.release(s.pool)
+		s.held = false
+	}
+}
+
+// crawlerConcurrency returns how many products of the crawler may be crawled
+// at once, defaulting to the global cap
+func (c *CrawlerService) crawlerConcurrency(crawlerName string) int {
+	site, err := c.registry.Site(crawlerName)
+	if err == nil && site.Concurrency > 0 {
+		return site.Concurrency
+	}
+
+	return c.cfg.NumCrawlers
+}
+
+// crawlerBatchSize returns how many products of the crawler are handed to a
+// single run
+func (c *CrawlerService) crawlerBatchSize(crawlerName string) int {
+	site, err := c.registry.Site(crawlerName)
+	if err != nil {
+		return 1
+	}
+
+	return site.BatchSize
+}
+
+func atLeastOne(n int) int {
+	if n < 1 {
+		return 1
+	}
+
+	return n
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func noBatching(string) int { return 1 }
+
+func TestDispatcherRoundRobin(t *testing.T) {
+	products := []entities.Product{
+		{Description: "ml-1", CrawlerName: "mercado-livre"},
+		{Description: "ml-2", CrawlerName: "mercado-livre"},
+		{Description: "ml-3", CrawlerName: "mercado-livre"},
+		{Description: "kabum-1", CrawlerName: "kabum"},
+		{Description: "amazon-1", CrawlerName: "amazon"},
+		{Description: "kabum-2", CrawlerName: "kabum"},
+	}
+	d := newDispatcher(products, 10, func(string) int { return 10 }, noBatching)
+
+	order := []string{}
+	for {
+		_, job, ok := d.nextJob()
+		if !ok {
+			break
+		}
+		order = append(order, job[0].Description)
+	}
+
+	assert.Equal(t, []string{"ml-1", "kabum-1", "amazon-1", "ml-2", "kabum-2", "ml-3"}, order)
+	assert.False(t, d.hasPending())
+}
+
+func TestDispatcherLimits(t *testing.T) {
+	products := []entities.Product{
+		{Description: "ml-1", CrawlerName: "mercado-livre"},
+		{Description: "ml-2", CrawlerName: "mercado-livre"},
+		{Description: "kabum-1", CrawlerName: "kabum"},
+		{Description: "kabum-2", CrawlerName: "kabum"},
+		{Description: "amazon-1", CrawlerName: "amazon"},
+	}
+	crawlerLimits := map[string]int{"mercado-livre": 1, "kabum": 2, "amazon": 2}
+	d := newDispatcher(products, 3, func(crawlerName string) int { return crawlerLimits[crawlerName] }, noBatching)
+
+	_, job, ok := d.nextJob()
+	assert.True(t, ok)
+	assert.Equal(t, "ml-1", job[0].Description)
+	_, job, _ = d.nextJob()
+	assert.Equal(t, "kabum-1", job[0].Description)
+	amazonPool, job, _ := d.nextJob()
+	assert.Equal(t, "amazon-1", job[0].Description)
+
+	_, _, ok = d.nextJob()
+	assert.False(t, ok, "global limit reached")
+
+	d.release(amazonPool)
+	_, job, ok = d.nextJob()
+	assert.True(t, ok)
+	assert.Equal(t, "kabum-2", job[0].Description, "mercado-livre is still at its own limit")
+}
+
+func TestDispatcherBatches(t *testing.T) {
+	products := []entities.Product{
+		{Description: "ml-1", CrawlerName: "mercado-livre"},
+		{Description: "ml-2", CrawlerName: "mercado-livre"},
+		{Description: "ml-3", CrawlerName: "mercado-livre"},
+		{Description: "kabum-1", CrawlerName: "kabum"},
+	}
+	batchSizes := map[string]int{"mercado-livre": 2}
+	d := newDispatcher(products, 10, func(string) int { return 10 }, func(crawlerName string) int { return batchSizes[crawlerName] })
+
+	_, job, _ := d.nextJob()
+	assert.Equal(t, products[0:2], job)
+	_, job, _ = d.nextJob()
+	assert.Equal(t, products[3:4], job)
+	_, job, _ = d.nextJob()
+	assert.Equal(t, products[2:3], job)
+	assert.False(t, d.hasPending())
+}
+
+func TestDispatcherPausedSlot(t *testing.T) {
+	products := []entities.Product{
+		{Description: "ml-1", CrawlerName: "mercado-livre"},
+		{Description: "kabum-1", CrawlerName: "kabum"},
+		{Description: "kabum-2", CrawlerName: "kabum"},
+	}
+	d := newDispatcher(products, 1, func(string) int { return 10 }, noBatching)
+
+	mlPool, _, _ := d.nextJob()
+	d.release(mlPool)
+	kabumPool, job, ok := d.nextJob()
+	assert.True(t, ok, "a paused job does not hold its slot")
+	assert.Equal(t, "kabum-1", job[0].Description)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.acquire(ctx, mlPool), context.DeadlineExceeded)
+
+	acquired := make(chan error)
+	go func() { acquired <- d.acquire(context.Background(), mlPool) }()
+	assert.Eventually(t, func() bool {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return d.waiting == 1
+	}, time.Second, time.Millisecond)
+
+	d.release(kabumPool)
+	assert.NoError(t, <-acquired)
+	_, _, ok = d.nextJob()
+	assert.False(t, ok, "the paused job takes its slot back before new jobs start")
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultLeaseDuration = 10 * time.Minute
+	minLeaseRenewal      = time.Second
+)
+
+// ExecuteWithLeases claims batches of due products until none are left, so
+// several orchestrators can share the same catalog. Leases are renewed while a
+// batch is being crawled and released once its products are checked; products
+// skipped or interrupted keep their lease until it expires, which keeps them
+// from being claimed again in the same run. Cancelling ctx stops new batches
+// from being claimed, and the current batch is drained as in Execute
+func (c *CrawlerService) ExecuteWithLeases(ctx context.Context) error {
+	c.logger.AddFields(map[string]interface{}{"process_id": uuid.New().String()})
+	c.logger.Info("Iniciando processamento dos produtos com concessões")
+	c.stats.reset()
+
+	owner := c.leaseOwner()
+	batchSize := c.leaseBatchSize()
+	leaseDuration := c.leaseDuration()
+
+	for ctx.Err() == nil {
+		products, err := c.db.Products().ClaimDueProducts(ctx, owner, batchSize, leaseDuration, c.cfg.DefaultCheckInterval)
+		if err != nil {
+			return err
+		}
+		if len(products) == 0 {
+			c.logger.Info("Nenhum produto pendente para reservar")
+			return nil
+		}
+		c.logger.Info(fmt.Sprintf("%d produtos reservados por %s", len(products), owner))
+
+		stopRenewal := c.renewLeases(owner, productIDs(products), leaseDuration)
+		checkedProducts, err := c.processProducts(ctx, products)
+		stopRenewal()
+		if err != nil {
+			return err
+		}
+
+		// the batch is over, so leases are released even after a shutdown request
+		err = c.db.Products().ReleaseLeases(context.Background(), owner, checkedProducts)
+		if err != nil {
+			c.logger.Error(fmt.Sprintf("Erro ao liberar produtos reservados por %s", owner))
+			c.logger.Error(err.Error())
+		}
+	}
+
+	return nil
+}
+
+// renewLeases keeps the leases alive in background until the returned
+// function is called
+func (c *CrawlerService) renewLeases(owner string, ids []uuid.UUID, leaseDuration time.Duration) func() {
+	renewEvery := leaseDuration / 3
+	if renewEvery < minLeaseRenewal {
+		renewEvery = minLeaseRenewal
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(renewEvery)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				err := c.db.Products().RenewLeases(context.Background(), owner, ids, leaseDuration)
+				if err != nil {
+					c.logger.Error(fmt.Sprintf("Erro ao renovar produtos reservados por %s", owner))
+					c.logger.Error(err.Error())
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		wg.Wait()
+	}
+}
+
+func (c *CrawlerService) leaseOwner() string {
+	if c.cfg.Lease.Owner != "" {
+		return c.cfg.Lease.Owner
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "orchestrator"
+	}
+
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+func (c *CrawlerService) leaseBatchSize() int {
+	if c.cfg.Lease.BatchSize > 0 {
+		return c.cfg.Lease.BatchSize
+	}
+
+	return c.cfg.NumCrawlers * 2
+}
+
+func (c *CrawlerService) leaseDuration() time.Duration {
+	if c.cfg.Lease.Duration > 0 {
+		return c.cfg.Lease.Duration
+	}
+
+	return defaultLeaseDuration
+}
+
+func productIDs(products []entities.Product) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+
+	return ids
+}
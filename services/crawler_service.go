@@ -1,50 +1,67 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"sync"
+	"time"
 
 	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
 	"github.com/JoaoLeal92/product-monitor-orchestrator/contracts"
 	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
-	crawlerparser "github.com/JoaoLeal92/product-monitor-orchestrator/infra/crawlerParser"
 	"github.com/google/uuid"
 )
 
 type CrawlerService struct {
-	parser          *crawlerparser.ResultParser
+	parsers         contracts.ResultParserRegistry
 	cfg             *config.CrawlerConfig
+	db              contracts.RepoManager
 	notificationSvc contracts.ProductNotificationService
 	logger          contracts.LoggerContract
 	crawler         contracts.Crawler
+	registry        contracts.CrawlerRegistry
+	limiter         *rateLimiter
+	stats           runStatsCollector
+	random          *lockedRand
+	secondPass      secondPass
+	delisted        collectedProducts
+	failed          collectedProducts
+	breakers        *circuitBreakers
 }
 
 type processingChannels struct {
-	CrawlerJobsChan      chan entities.Product
 	CrawlerResultsChan   chan crawlerChanResult
 	EndProcessingChannel chan bool
 }
 
 type crawlerChanResult struct {
-	CrawlerResult string
+	CrawlerResult *entities.CrawlerResult
 	Product       entities.Product
 }
 
-func NewCrawlerService(parser *crawlerparser.ResultParser, cfg *config.CrawlerConfig, notificationSvc contracts.ProductNotificationService, logger contracts.LoggerContract, crawler contracts.Crawler) *CrawlerService {
+func NewCrawlerService(parsers contracts.ResultParserRegistry, cfg *config.CrawlerConfig, db contracts.RepoManager, notificationSvc contracts.ProductNotificationService, logger contracts.LoggerContract, crawler contracts.Crawler, registry contracts.CrawlerRegistry) *CrawlerService {
 	return &CrawlerService{
-		parser:          parser,
+		parsers:         parsers,
 		cfg:             cfg,
+		db:              db,
 		notificationSvc: notificationSvc,
 		logger:          logger,
 		crawler:         crawler,
+		registry:        registry,
+		limiter:         newRateLimiter(),
+		random:          newLockedRand(),
+		breakers:        newCircuitBreakers(cfg.CircuitBreaker, logger),
 	}
 }
 
-func (c *CrawlerService) Execute(products []entities.Product) error {
+// Execute crawls the products. Cancelling ctx stops new products from being
+// crawled; the ones already running get up to ShutdownTimeout to finish
+func (c *CrawlerService) Execute(ctx context.Context, products []entities.Product) error {
 	c.logger.AddFields(map[string]interface{}{"process_id": uuid.New().String()})
 	c.logger.Info("Iniciando processamento dos produtos")
+	c.stats.reset()
 
-	err := c.processProducts(products)
+	_, err := c.processProducts(ctx, products)
 	if err != nil {
 		return err
 	}
@@ -52,23 +69,100 @@ func (c *CrawlerService) Execute(products []entities.Product) error {
 	return nil
 }
 
-func (c *CrawlerService) processProducts(productsRelations []entities.Product) error {
+// Stats returns the counters of the current or last run
+func (c *CrawlerService) Stats() RunStats {
+	return c.stats.snapshot()
+}
+
+// processProducts crawls the products and returns the ids of the ones marked
+// as checked, which are the ones crawled and the ones that failed for good
+func (c *CrawlerService) processProducts(ctx context.Context, productsRelations []entities.Product) ([]uuid.UUID, error) {
 	c.logger.Info("Processando produtos")
 
-	numWorkers := c.cfg.NumCrawlers
-	processingChannels := c.setupProcessChannels(numWorkers)
+	runCtx, cancelRun := c.drainContext(ctx)
+	defer cancelRun()
+
+	c.secondPass.reset(c.cfg.Retry.SecondPass)
+	c.delisted.reset()
+	c.failed.reset()
+	productsRelations = c.resolveStores(productsRelations)
+	products, productWatchers := dedupeByLink(productsRelations, c.registry.CrawlerForLink)
+	if len(products) < len(productsRelations) {
+		c.logger.Info(fmt.Sprintf("%d produtos compartilham links e serão verificados em %d execuções", len(productsRelations), len(products)))
+	}
+
+	checkedProducts := []uuid.UUID{}
+	c.runPass(ctx, runCtx, products, productWatchers, &checkedProducts)
+
+	deferred := c.secondPass.take()
+	if len(deferred) > 0 && ctx.Err() == nil {
+		c.logger.Info(fmt.Sprintf("Segunda passagem para %d produtos com falhas temporárias", len(deferred)))
+		products = make([]entities.Product, 0, len(deferred))
+		for _, d := range deferred {
+			products = append(products, d.product)
+		}
+		c.runPass(ctx, runCtx, products, productWatchers, &checkedProducts)
+	} else {
+		for _, d := range deferred {
+			site, err := c.registry.Site(d.product.CrawlerName)
+			if err != nil {
+				c.logCrawlerError(d.product, err)
+				continue
+			}
+			c.failProduct(ctx, site, d.product, d.err)
+		}
+	}
+
+	c.storeDelisted(runCtx, productWatchers, &checkedProducts)
+	c.markFailedChecked(runCtx, productWatchers, &checkedProducts)
+
+	if ctx.Err() != nil {
+		c.logger.Warn("Processamento interrompido antes de concluir todos os produtos")
+	}
+
+	return checkedProducts, nil
+}
 
-	go c.allocateCrawlerJobs(productsRelations, processingChannels.CrawlerJobsChan)
-	go c.processResultsFromJobs(processingChannels)
-	c.createWorkerPool(numWorkers, processingChannels)
+// runPass crawls the products and stores their results for every watcher,
+// returning once every result is stored
+func (c *CrawlerService) runPass(ctx context.Context, runCtx context.Context, products []entities.Product, productWatchers watchers, checkedProducts *[]uuid.UUID) {
+	processingChannels := c.setupProcessChannels(c.cfg.NumCrawlers)
+
+	go c.processResultsFromJobs(runCtx, processingChannels, productWatchers, checkedProducts)
+	c.dispatchCrawlerJobs(ctx, runCtx, products, processingChannels)
 	<-processingChannels.EndProcessingChannel
+}
 
-	return nil
+// drainContext returns the context for the crawlers and results of a run. It
+// outlives ctx by ShutdownTimeout, so in-flight jobs can finish and have their
+// results stored after a shutdown is requested
+func (c *CrawlerService) drainContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	runCtx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		select {
+		case <-runCtx.Done():
+			return
+		case <-ctx.Done():
+		}
+
+		c.logger.Warn(fmt.Sprintf("Interrupção solicitada, aguardando até %v pelos crawlers em execução", c.cfg.ShutdownTimeout))
+		timer := time.NewTimer(c.cfg.ShutdownTimeout)
+		defer timer.Stop()
+
+		select {
+		case <-runCtx.Done():
+		case <-timer.C:
+			c.logger.Warn("Tempo de espera esgotado, encerrando crawlers em execução")
+			cancel()
+		}
+	}()
+
+	return runCtx, cancel
 }
 
 func (c *CrawlerService) setupProcessChannels(numWorkers int) processingChannels {
 	processingChannels := processingChannels{
-		CrawlerJobsChan:      make(chan entities.Product, numWorkers),
 		CrawlerResultsChan:   make(chan crawlerChanResult, numWorkers),
 		EndProcessingChannel: make(chan bool),
 	}
@@ -76,79 +170,332 @@ func (c *CrawlerService) setupProcessChannels(numWorkers int) processingChannels
 	return processingChannels
 }
 
-func (c *CrawlerService) allocateCrawlerJobs(products []entities.Product, crawlerJobsChan chan entities.Product) {
-	defer close(crawlerJobsChan)
-	for i := 0; i < len(products); i++ {
-		crawlerJobsChan <- products[i]
+func (c *CrawlerService) crawlProduct(ctx context.Context, job entities.Product, slot *jobSlot, processingChannels processingChannels) {
+	c.logger.Info(fmt.Sprintf("%s Iniciando processamento do produto %s", job.ID, job.Description))
+
+	site, err := c.registry.Site(job.CrawlerName)
+	if err != nil {
+		c.logCrawlerError(job, err)
+		return
 	}
+
+	var crawlerResult *entities.CrawlerResult
+	err = c.retry(ctx, c.retryPolicy(site), job.ID.String(), slot, func() error {
+		var err error
+		crawlerResult, err = c.crawlAttempt(ctx, site, job, slot)
+		return err
+	})
+	if err != nil {
+		c.failProduct(ctx, site, job, err)
+		return
+	}
+
+	processingChannels.CrawlerResultsChan <- crawlerChanResult{
+		CrawlerResult: crawlerResult,
+		Product:       job,
+	}
+}
+
+// crawlAttempt sets the crawler environment up and, unless its circuit
+// breaker is open, waits for the crawler rate limits without holding the
+// dispatcher slot, runs it and parses its output. Failures the crawler reports
+// in its output are counted by the breaker, setup failures are not
+func (c *CrawlerService) crawlAttempt(ctx context.Context, site config.CrawlerSiteConfig, job entities.Product, slot *jobSlot) (*entities.CrawlerResult, error) {
+	err := c.crawler.SetupCrawlerEnv(ctx, site, job.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	recordOutcome, err := c.breakers.allow(site)
+	if err != nil {
+		return nil, err
+	}
+
+	crawlerResult, err := func() (*entities.CrawlerResult, error) {
+		delay, err := c.limiter.Reserve(site)
+		if err != nil {
+			return nil, err
+		}
+		err = slot.pause(ctx, delay)
+		if err != nil {
+			return nil, err
+		}
+
+		crawlerOutput, err := c.crawler.RunCrawler(ctx, site, job)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.parseOutput(site, crawlerOutput)
+	}()
+	recordOutcome(err)
+
+	return crawlerResult, err
 }
 
-func (c *CrawlerService) createWorkerPool(numWorkers int, processingChannels processingChannels) {
-	var wg sync.WaitGroup
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go c.crawlProduct(processingChannels, &wg)
+// parseOutput reads the output of a crawler run in the output format of the
+// crawler. Failures the crawler reports through the protocol are returned as
+// its errors
+func (c *CrawlerService) parseOutput(site config.CrawlerSiteConfig, crawlerOutput string) (*entities.CrawlerResult, error) {
+	parser, err := c.parsers.Parser(site.OutputFormat)
+	if err != nil {
+		return nil, err
 	}
-	wg.Wait()
-	close(processingChannels.CrawlerResultsChan)
+
+	return parser.ParseCrawlerResult(crawlerOutput)
 }
 
-func (c *CrawlerService) crawlProduct(processingChannels processingChannels, wg *sync.WaitGroup) {
-	for job := range processingChannels.CrawlerJobsChan {
-		c.logger.Info(fmt.Sprintf("%s Iniciando processamento do produto %s", job.ID, job.Description))
+// crawlBatch crawls products of the same crawler in a single run. Products
+// left without a result, or whose reported failure is retryable, are crawled
+// again, as a smaller batch, while the retry policy allows it. Every run takes a rate limit token for each of its
+// products and starts once the last of them is due. A run counts as failed
+// for the circuit breaker when it fails or a crawler reports a failure for
+// one of its products
+func (c *CrawlerService) crawlBatch(ctx context.Context, jobs []entities.Product, slot *jobSlot, processingChannels processingChannels) {
+	c.logger.Info(fmt.Sprintf("Iniciando processamento de lote de %d produtos do crawler %s", len(jobs), jobs[0].CrawlerName))
+
+	site, err := c.registry.Site(jobs[0].CrawlerName)
+	if err != nil {
+		for _, job := range jobs {
+			c.logCrawlerError(job, err)
+		}
+		return
+	}
 
-		crawlerPath, err := job.GetCrawlerPath(c.cfg)
+	remaining := make([]entities.Product, 0, len(jobs))
+	for _, job := range jobs {
+		err := c.crawler.SetupCrawlerEnv(ctx, site, job.ID.String())
 		if err != nil {
-			c.logger.Error(fmt.Sprintf("erro na busca de produto de id %s para usuário %s", job.ID.String(), job.UserID.String()))
-			c.logger.Error(err.Error())
+			c.logCrawlerError(job, err)
 			continue
 		}
+		remaining = append(remaining, job)
+	}
+	if len(remaining) == 0 {
+		return
+	}
 
-		err = c.crawler.SetupCrawlerEnv(crawlerPath, job.ID.String())
+	label := fmt.Sprintf("Lote do crawler %s", site.Name)
+	policy := c.retryPolicy(site)
+	// failures the crawler reported for the products of the last run
+	var failures map[uuid.UUID]error
+	err = c.retry(ctx, policy, label, slot, func() error {
+		failures = make(map[uuid.UUID]error)
+		recordOutcome, err := c.breakers.allow(site)
 		if err != nil {
-			c.logger.Error(fmt.Sprintf("erro na busca de produto de id %s para usuário %s", job.ID.String(), job.UserID.String()))
-			c.logger.Error(err.Error())
-			continue
+			return err
+		}
+
+		var delay time.Duration
+		remaining, delay = c.reserveBatch(site, remaining)
+		if len(remaining) == 0 {
+			recordOutcome(entities.ErrDailyBudgetExhausted)
+			return nil
+		}
+		err = slot.pause(ctx, delay)
+		if err != nil {
+			recordOutcome(err)
+			return err
 		}
 
-		crawlerOutput, err := c.crawler.RunCrawler(crawlerPath, job)
+		// a failed run may still have produced results for part of the batch
+		crawlerOutputs, err := c.crawler.RunCrawlerBatch(ctx, site, remaining)
+
+		missing := []entities.Product{}
+		var reported, retried error
+		for _, job := range remaining {
+			crawlerOutput, ok := crawlerOutputs[job.ID]
+			if !ok {
+				missing = append(missing, job)
+				continue
+			}
+
+			crawlerResult, parseErr := c.parseOutput(site, crawlerOutput)
+			if parseErr == nil {
+				processingChannels.CrawlerResultsChan <- crawlerChanResult{
+					CrawlerResult: crawlerResult,
+					Product:       job,
+				}
+				continue
+			}
+
+			if reported == nil && countsForBreaker(parseErr) {
+				reported = parseErr
+			}
+			if !policy.retryable(parseErr) {
+				c.logCrawlerError(job, parseErr)
+				continue
+			}
+			missing = append(missing, job)
+			failures[job.ID] = parseErr
+			if retried == nil {
+				retried = parseErr
+			}
+		}
+		remaining = missing
 		if err != nil {
-			c.logger.Error(fmt.Sprintf("erro na busca de produto de id %s para usuário %s", job.ID.String(), job.UserID.String()))
-			c.logger.Error(err.Error())
+			recordOutcome(err)
+		} else {
+			recordOutcome(reported)
+		}
+
+		if len(remaining) == 0 {
+			return nil
+		}
+		if err == nil {
+			err = retried
+		}
+		if err == nil {
+			err = &entities.CrawlerError{
+				Kind:    entities.FailureUnknown,
+				Message: fmt.Sprintf("crawler %s returned no result for the product", site.Name),
+			}
+		}
+
+		return err
+	})
+	if err != nil {
+		for _, job := range remaining {
+			jobErr, ok := failures[job.ID]
+			if !ok {
+				jobErr = err
+			}
+			c.failProduct(ctx, site, job, jobErr)
+		}
+	}
+}
+
+// reserveBatch books a rate limit token for each product of a batch run,
+// returning the products within the daily budget and how long to wait for the
+// last token
+func (c *CrawlerService) reserveBatch(site config.CrawlerSiteConfig, jobs []entities.Product) ([]entities.Product, time.Duration) {
+	reserved := make([]entities.Product, 0, len(jobs))
+	var batchDelay time.Duration
+	for _, job := range jobs {
+		delay, err := c.limiter.Reserve(site)
+		if err != nil {
+			c.logBudgetExhausted(job)
 			continue
 		}
+		reserved = append(reserved, job)
+		if delay > batchDelay {
+			batchDelay = delay
+		}
+	}
 
-		crawlerResult := crawlerChanResult{
-			CrawlerResult: crawlerOutput,
-			Product:       job,
+	return reserved, batchDelay
+}
+
+func (c *CrawlerService) logBudgetExhausted(job entities.Product) {
+	c.logger.Warn(fmt.Sprintf("%s Limite diário de requisições do crawler %s atingido, produto ignorado", job.ID, job.CrawlerName))
+	c.stats.add(func(stats *RunStats) { stats.Skipped++ })
+}
+
+// failProduct leaves products with retryable failures for the second pass
+// when it is enabled, and records the failure otherwise. Products not crawled
+// for the daily budget or an open circuit breaker are skipped, or deferred to
+// the second pass in the case of the circuit breaker
+func (c *CrawlerService) failProduct(ctx context.Context, site config.CrawlerSiteConfig, job entities.Product, err error) {
+	if errors.Is(err, entities.ErrDailyBudgetExhausted) {
+		c.logBudgetExhausted(job)
+		return
+	}
+	if errors.Is(err, entities.ErrCircuitOpen) {
+		if ctx.Err() == nil && c.secondPass.add(job, err) {
+			c.logger.Warn(fmt.Sprintf("%s Circuito do crawler %s aberto, produto adiado para o fim da execução", job.ID, job.CrawlerName))
+			return
+		}
+		c.logger.Warn(fmt.Sprintf("%s Circuito do crawler %s aberto, produto ignorado", job.ID, job.CrawlerName))
+		c.stats.add(func(stats *RunStats) { stats.Skipped++ })
+		return
+	}
+
+	if ctx.Err() == nil && c.retryPolicy(site).retryable(err) && c.secondPass.add(job, err) {
+		c.logger.Warn(fmt.Sprintf("%s Falha temporária (%s), produto será tentado novamente ao fim da execução", job.ID, entities.FailureKindOf(err)))
+		return
+	}
+
+	c.logCrawlerError(job, err)
+}
+
+// logCrawlerError records a failed product. Failures on the website side are
+// expected from time to time and logged as warnings, the ones that need the
+// crawler or its setup to be fixed as errors. Products not interrupted by a
+// shutdown are marked as checked once the run is over
+func (c *CrawlerService) logCrawlerError(job entities.Product, err error) {
+	kind := entities.FailureKindOf(err)
+	switch {
+	case kind == entities.FailureNotFound:
+		c.delisted.add(job)
+	case !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded):
+		c.failed.add(job)
+	}
+	c.stats.add(func(stats *RunStats) {
+		stats.Failed++
+		if kind == entities.FailureTimeout {
+			stats.TimedOut++
 		}
+	})
 
-		processingChannels.CrawlerResultsChan <- crawlerResult
+	message := fmt.Sprintf("erro na busca de produto de id %s para usuário %s (%s)", job.ID.String(), job.UserID.String(), kind)
+	switch kind {
+	case entities.FailureBlocked, entities.FailureNotFound, entities.FailureNetwork, entities.FailureTimeout:
+		c.logger.Warn(message)
+		c.logger.Warn(err.Error())
+	default:
+		c.logger.Error(message)
+		c.logger.Error(err.Error())
 	}
-	wg.Done()
 }
 
-func (c *CrawlerService) processResultsFromJobs(processingChannels processingChannels) {
+func (c *CrawlerService) processResultsFromJobs(ctx context.Context, processingChannels processingChannels, productWatchers watchers, checkedProducts *[]uuid.UUID) {
 	defer close(processingChannels.EndProcessingChannel)
 
 	for channelResult := range processingChannels.CrawlerResultsChan {
 		c.logger.Info(fmt.Sprintf("%s Pegando resultado para o produto %s", channelResult.Product.ID, channelResult.Product.Description))
 
-		crawlerResult := c.parser.ParseCrawlerResult(channelResult.CrawlerResult)
-		productSearchResult := entities.ProductSearchResult{
-			ProductID:     channelResult.Product.ID,
-			UserID:        channelResult.Product.UserID,
-			Price:         crawlerResult.Price,
-			OriginalPrice: crawlerResult.OriginalPrice,
-			Discount:      crawlerResult.Discount,
-		}
+		crawlerResult := channelResult.CrawlerResult
+		c.stats.add(func(stats *RunStats) { stats.Crawled++ })
 
-		err := c.notificationSvc.Execute(&channelResult.Product, &productSearchResult)
-		if err != nil {
-			c.logger.Error(fmt.Sprintf("%s: Erro na criação de histórico", channelResult.Product.ID))
-			c.logger.Error(err.Error())
+		for _, product := range productWatchers.of(channelResult.Product) {
+			productSearchResult := entities.ProductSearchResult{
+				ProductID:     product.ID,
+				UserID:        product.UserID,
+				Price:         crawlerResult.Price,
+				OriginalPrice: crawlerResult.OriginalPrice,
+				Discount:      crawlerResult.Discount,
+				Availability:  crawlerResult.Availability,
+			}
+
+			if c.storeResult(ctx, product, &productSearchResult) {
+				*checkedProducts = append(*checkedProducts, product.ID)
+			}
 		}
 	}
 	c.logger.Info("Finalizando processamento dos resultados")
 	processingChannels.EndProcessingChannel <- true
 }
+
+// storeResult runs the notification service for the product and marks it as
+// checked, returning whether it was
+func (c *CrawlerService) storeResult(ctx context.Context, product entities.Product, productSearchResult *entities.ProductSearchResult) bool {
+	err := c.notificationSvc.Execute(ctx, &product, productSearchResult)
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("%s: Erro na criação de histórico", product.ID))
+		c.logger.Error(err.Error())
+	}
+
+	return c.markChecked(ctx, product)
+}
+
+// markChecked updates the last check of the product, returning whether it was
+func (c *CrawlerService) markChecked(ctx context.Context, product entities.Product) bool {
+	err := c.db.Products().UpdateLastCheckedAt(ctx, product.ID, time.Now())
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("%s: Erro ao registrar horário da verificação", product.ID))
+		c.logger.Error(err.Error())
+		return false
+	}
+
+	return true
+}
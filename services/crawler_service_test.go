@@ -1,13 +1,18 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
 	mocks "github.com/JoaoLeal92/product-monitor-orchestrator/contracts/mocks"
 	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
 	crawlerparser "github.com/JoaoLeal92/product-monitor-orchestrator/infra/crawlerParser"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -16,10 +21,12 @@ func TestCrawlerService(t *testing.T) {
 	mockLogger := mocks.NewLoggerContract(t)
 	mockCrawler := mocks.NewCrawler(t)
 	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
 
-	parser := crawlerparser.NewResultParser()
+	parser := crawlerparser.NewRegistry()
 	cfg := config.CrawlerConfig{
-		Amazon:      "test-amazon-crawler",
 		NumCrawlers: 1,
 	}
 	mockProducts := []entities.Product{
@@ -37,29 +44,113 @@ func TestCrawlerService(t *testing.T) {
 
 	mockLogger.On("Info", mock.Anything).Return(nil)
 	mockLogger.On("AddFields", mock.Anything).Return(nil)
-	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything).Return(nil)
-	mockCrawler.On("RunCrawler", mock.Anything, mockProducts[0]).Return("Product(price=1000, original_price=1500, discount=None, link='http://test-link-1.com')", nil).Once()
-	mockCrawler.On("RunCrawler", mock.Anything, mockProducts[1]).Return("Product(price=1500, original_price=1500, discount=None, link='http://test-link-2.com')", nil).Once()
-	mockProductNotificationSvc.On("Execute", mock.Anything, mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon"}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[0]).Return("Product(price=1000, original_price=1500, discount=None, link='http://test-link-1.com')", nil).Once()
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[1]).Return("Product(price=1500, original_price=1500, discount=None, link='http://test-link-2.com')", nil).Once()
+	mockProductNotificationSvc.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	crawlerService := NewCrawlerService(parser, &cfg, mockProductNotificationSvc, mockLogger, mockCrawler)
-	err := crawlerService.Execute(mockProducts)
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
 
 	require.NoError(t, err)
-	mockProductNotificationSvc.AssertCalled(t, "Execute", mock.Anything, mock.Anything)
-	mockCrawler.AssertCalled(t, "SetupCrawlerEnv", mock.Anything, mock.Anything)
-	mockCrawler.AssertCalled(t, "RunCrawler", mock.Anything, mockProducts[0])
-	mockCrawler.AssertCalled(t, "RunCrawler", mock.Anything, mockProducts[1])
+	mockProductNotificationSvc.AssertCalled(t, "Execute", mock.Anything, mock.Anything, mock.Anything)
+	mockCrawler.AssertCalled(t, "SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything)
+	mockCrawler.AssertCalled(t, "RunCrawler", mock.Anything, mock.Anything, mockProducts[0])
+	mockCrawler.AssertCalled(t, "RunCrawler", mock.Anything, mock.Anything, mockProducts[1])
+	mockProductsRepo.AssertNumberOfCalls(t, "UpdateLastCheckedAt", 2)
+}
+
+func TestCrawlerServiceWithCrawlerOutputFormat(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+	mockParsers := mocks.NewResultParserRegistry(t)
+	mockParser := mocks.NewResultParser(t)
+
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+	}
+	mockProducts := []entities.Product{
+		{
+			Description: "test-product-1",
+			MaxPrice:    1000,
+			CrawlerName: "amazon",
+		},
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon", OutputFormat: "kv"}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[0]).Return("price=900 original_price=1500", nil)
+	mockParsers.On("Parser", "kv").Return(mockParser, nil)
+	mockParser.On("ParseCrawlerResult", "price=900 original_price=1500").Return(&entities.CrawlerResult{Price: 900, OriginalPrice: 1500}, nil)
+	mockProductNotificationSvc.On("Execute", mock.Anything, mock.Anything, mock.MatchedBy(func(result *entities.ProductSearchResult) bool {
+		return result.Price == 900 && result.OriginalPrice == 1500
+	})).Return(nil)
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(mockParsers, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	mockProductsRepo.AssertNumberOfCalls(t, "UpdateLastCheckedAt", 1)
+}
+
+func TestCrawlerServiceWithUnknownOutputFormat(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+	}
+	mockProducts := []entities.Product{
+		{
+			Description: "test-product-1",
+			MaxPrice:    1000,
+			CrawlerName: "amazon",
+		},
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Error", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon", OutputFormat: "xml"}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[0]).Return("<price>900</price>", nil)
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(crawlerparser.NewRegistry(), &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	mockProductNotificationSvc.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything, mock.Anything)
+	mockLogger.AssertCalled(t, "Error", "unknown crawler output format: xml")
+	mockProductsRepo.AssertNumberOfCalls(t, "UpdateLastCheckedAt", 1)
 }
 
 func TestCrawlerServiceWithCrawlerEnvError(t *testing.T) {
 	mockLogger := mocks.NewLoggerContract(t)
 	mockCrawler := mocks.NewCrawler(t)
 	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
 
-	parser := crawlerparser.NewResultParser()
+	parser := crawlerparser.NewRegistry()
 	cfg := config.CrawlerConfig{
-		Amazon:      "test-amazon-crawler",
 		NumCrawlers: 1,
 	}
 	mockProducts := []entities.Product{
@@ -73,26 +164,438 @@ func TestCrawlerServiceWithCrawlerEnvError(t *testing.T) {
 	mockLogger.On("Info", mock.Anything).Return(nil)
 	mockLogger.On("Error", mock.Anything).Return(nil)
 	mockLogger.On("AddFields", mock.Anything).Return(nil)
-	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything).Return(errors.New("Env setup error"))
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon"}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("Env setup error"))
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	crawlerService := NewCrawlerService(parser, &cfg, mockProductNotificationSvc, mockLogger, mockCrawler)
-	err := crawlerService.Execute(mockProducts)
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
 
 	require.NoError(t, err)
-	mockProductNotificationSvc.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
-	mockCrawler.AssertCalled(t, "SetupCrawlerEnv", mock.Anything, mock.Anything)
-	mockCrawler.AssertNotCalled(t, "RunCrawler", mock.Anything, mockProducts[0])
+	mockProductNotificationSvc.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything, mock.Anything)
+	mockCrawler.AssertCalled(t, "SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything)
+	mockCrawler.AssertNotCalled(t, "RunCrawler", mock.Anything, mock.Anything, mockProducts[0])
+	mockProductsRepo.AssertNumberOfCalls(t, "UpdateLastCheckedAt", 1)
 }
 
 func TestCrawlerServiceWithCrawlerRunError(t *testing.T) {
 	mockLogger := mocks.NewLoggerContract(t)
 	mockCrawler := mocks.NewCrawler(t)
 	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+	}
+	mockProducts := []entities.Product{
+		{
+			Description: "test-product-1",
+			MaxPrice:    1000,
+			CrawlerName: "amazon",
+		},
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Error", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon"}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[0]).Return("", errors.New("Crawler run error")).Once()
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	mockProductNotificationSvc.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything, mock.Anything)
+	mockCrawler.AssertCalled(t, "SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything)
+	mockCrawler.AssertCalled(t, "RunCrawler", mock.Anything, mock.Anything, mockProducts[0])
+	mockProductsRepo.AssertNumberOfCalls(t, "UpdateLastCheckedAt", 1)
+}
+
+func TestCrawlerServiceWithLeases(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+		Lease: config.LeaseConfig{
+			Enabled:   true,
+			Owner:     "test-owner",
+			BatchSize: 2,
+			Duration:  time.Hour,
+		},
+	}
+	mockProducts := []entities.Product{
+		{
+			ID:          uuid.New(),
+			Description: "test-product-1",
+			MaxPrice:    1000,
+			CrawlerName: "amazon",
+		},
+		{
+			ID:          uuid.New(),
+			Description: "test-product-2",
+			MaxPrice:    1200,
+			CrawlerName: "amazon",
+		},
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Error", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon"}, nil)
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("ClaimDueProducts", mock.Anything, "test-owner", 2, time.Hour, mock.Anything).Return(mockProducts, nil).Once()
+	mockProductsRepo.On("ClaimDueProducts", mock.Anything, "test-owner", 2, time.Hour, mock.Anything).Return([]entities.Product{}, nil).Once()
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockProductsRepo.On("ReleaseLeases", mock.Anything, "test-owner", []uuid.UUID{mockProducts[0].ID, mockProducts[1].ID}).Return(nil).Once()
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[0]).Return("Product(price=1000, original_price=1500, discount=None, link='http://test-link-1.com')", nil).Once()
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[1]).Return("", errors.New("Crawler run error")).Once()
+	mockProductNotificationSvc.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.ExecuteWithLeases(context.Background())
+
+	require.NoError(t, err)
+	mockProductsRepo.AssertNumberOfCalls(t, "ClaimDueProducts", 2)
+	mockProductsRepo.AssertNotCalled(t, "RenewLeases", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockProductsRepo.AssertCalled(t, "ReleaseLeases", mock.Anything, "test-owner", []uuid.UUID{mockProducts[0].ID, mockProducts[1].ID})
+}
+
+func TestCrawlerServiceWithCancelledContext(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+	}
+	mockProducts := []entities.Product{
+		{
+			Description: "test-product-1",
+			MaxPrice:    1000,
+			CrawlerName: "amazon",
+		},
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Warn", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon"}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(ctx, mockProducts)
+
+	require.NoError(t, err)
+	mockCrawler.AssertNotCalled(t, "SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything)
+	mockCrawler.AssertNotCalled(t, "RunCrawler", mock.Anything, mock.Anything, mock.Anything)
+	mockProductNotificationSvc.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCrawlerServiceWithCrawlerTimeout(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+	}
+	mockProducts := []entities.Product{
+		{
+			Description: "test-product-1",
+			MaxPrice:    1000,
+			CrawlerName: "amazon",
+		},
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Warn", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon"}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[0]).Return("", &entities.CrawlerError{Kind: entities.FailureTimeout, Message: "no result after 1s"}).Once()
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	assert.Equal(t, RunStats{Failed: 1, TimedOut: 1}, crawlerService.Stats())
+	mockProductNotificationSvc.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything, mock.Anything)
+	mockLogger.AssertCalled(t, "Warn", "timeout: no result after 1s")
+	mockLogger.AssertNotCalled(t, "Error", mock.Anything)
+	mockProductsRepo.AssertNumberOfCalls(t, "UpdateLastCheckedAt", 1)
+}
+
+func TestCrawlerServiceWithReportedFailure(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+	}
+	mockProducts := []entities.Product{
+		{
+			Description: "test-product-1",
+			MaxPrice:    1000,
+			CrawlerName: "amazon",
+		},
+		{
+			Description: "test-product-2",
+			MaxPrice:    1000,
+			CrawlerName: "amazon",
+		},
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Warn", mock.Anything).Return(nil)
+	mockLogger.On("Error", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon"}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[0]).Return(`{"schema_version": 1, "price": null, "error": {"code": "not_found", "message": "page returned 404"}}`, nil).Once()
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[1]).Return("", &entities.CrawlerError{Kind: entities.FailureLayoutChanged, Message: "price element not found"}).Once()
+
+	mockProductNotificationSvc.On("Execute", mock.Anything, mock.Anything, mock.MatchedBy(func(result *entities.ProductSearchResult) bool {
+		return result.Availability == entities.AvailabilityUnavailable && result.Price == 0
+	})).Return(nil).Once()
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	assert.Equal(t, RunStats{Failed: 2}, crawlerService.Stats())
+	mockLogger.AssertCalled(t, "Warn", "not_found: page returned 404")
+	mockLogger.AssertCalled(t, "Error", "layout_changed: price element not found")
+	mockProductNotificationSvc.AssertNumberOfCalls(t, "Execute", 1)
+	mockProductsRepo.AssertNumberOfCalls(t, "UpdateLastCheckedAt", 2)
+}
+
+func TestCrawlerServiceSlowCrawlerDoesNotStarveOthers(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 2,
+	}
+	slowProducts := []entities.Product{
+		{ID: uuid.New(), Description: "slow-product-1", CrawlerName: "mercado-livre"},
+		{ID: uuid.New(), Description: "slow-product-2", CrawlerName: "mercado-livre"},
+	}
+	fastProduct := entities.Product{ID: uuid.New(), Description: "fast-product", CrawlerName: "kabum"}
+	mockProducts := append(slowProducts, fastProduct)
+
+	releaseSlowCrawler := make(chan struct{})
+	fastCrawlerDone := make(chan struct{})
+	crawlerOutput := "Product(price=1000, original_price=1500, discount=None, link='http://test-link.com')"
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "mercado-livre").Return(config.CrawlerSiteConfig{Name: "mercado-livre", Concurrency: 1}, nil)
+	mockRegistry.On("Site", "kabum").Return(config.CrawlerSiteConfig{Name: "kabum"}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, slowProducts[0]).Run(func(mock.Arguments) {
+		<-releaseSlowCrawler
+	}).Return(crawlerOutput, nil).Once()
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, slowProducts[1]).Run(func(mock.Arguments) {
+		<-releaseSlowCrawler
+	}).Return(crawlerOutput, nil).Once()
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, fastProduct).Run(func(mock.Arguments) {
+		close(fastCrawlerDone)
+	}).Return(crawlerOutput, nil).Once()
+	mockProductNotificationSvc.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	go func() {
+		select {
+		case <-fastCrawlerDone:
+		case <-time.After(5 * time.Second):
+			t.Error("kabum product was starved by the slow crawler")
+		}
+		close(releaseSlowCrawler)
+	}()
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	assert.Equal(t, RunStats{Crawled: 3}, crawlerService.Stats())
+}
+
+func TestCrawlerServiceWithUnknownCrawler(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+	}
+	mockProducts := []entities.Product{
+		{
+			Description: "test-product-1",
+			MaxPrice:    1000,
+			CrawlerName: "unknown-store",
+		},
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Error", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "unknown-store").Return(config.CrawlerSiteConfig{}, fmt.Errorf("%w: %q", entities.ErrUnknownCrawler, "unknown-store"))
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	assert.Equal(t, RunStats{Failed: 1}, crawlerService.Stats())
+	mockLogger.AssertCalled(t, "Error", `unknown crawler: "unknown-store"`)
+	mockCrawler.AssertNotCalled(t, "SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything)
+	mockProductsRepo.AssertNumberOfCalls(t, "UpdateLastCheckedAt", 1)
+}
+
+func TestCrawlerServiceWithBatches(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+	}
+	mockProducts := []entities.Product{
+		{ID: uuid.New(), Description: "test-product-1", MaxPrice: 1000, CrawlerName: "amazon"},
+		{ID: uuid.New(), Description: "test-product-2", MaxPrice: 1200, CrawlerName: "amazon"},
+		{ID: uuid.New(), Description: "test-product-3", MaxPrice: 1200, CrawlerName: "amazon"},
+	}
+	crawlerOutputs := map[uuid.UUID]string{
+		mockProducts[0].ID: fmt.Sprintf(`{"schema_version": 1, "id": "%s", "price": 1000}`, mockProducts[0].ID),
+		mockProducts[1].ID: fmt.Sprintf(`{"schema_version": 1, "id": "%s", "price": 1500}`, mockProducts[1].ID),
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Error", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon", BatchSize: 3}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawlerBatch", mock.Anything, mock.Anything, mockProducts).Return(crawlerOutputs, nil).Once()
+	mockProductNotificationSvc.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	mockCrawler.AssertNotCalled(t, "RunCrawler", mock.Anything, mock.Anything, mock.Anything)
+	mockProductsRepo.AssertNumberOfCalls(t, "UpdateLastCheckedAt", 3)
+	assert.Equal(t, RunStats{Crawled: 2, Failed: 1}, crawlerService.Stats())
+	mockLogger.AssertCalled(t, "Error", "unknown: crawler amazon returned no result for the product")
+}
+
+func TestCrawlerServiceRetries(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+		Retry: config.RetryConfig{
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+		},
+	}
+	mockProducts := []entities.Product{
+		{
+			Description: "test-product-1",
+			MaxPrice:    1000,
+			CrawlerName: "amazon",
+		},
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Warn", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon"}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[0]).Return("", &entities.CrawlerError{Kind: entities.FailureNetwork}).Once()
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[0]).Return("Product(price=1000, original_price=1500, discount=None, link='http://test-link-1.com')", nil).Once()
+	mockProductNotificationSvc.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	mockCrawler.AssertNumberOfCalls(t, "RunCrawler", 2)
+	assert.Equal(t, RunStats{Crawled: 1}, crawlerService.Stats())
+}
+
+func TestCrawlerServiceRetriesReportedFailures(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
 
-	parser := crawlerparser.NewResultParser()
+	parser := crawlerparser.NewRegistry()
 	cfg := config.CrawlerConfig{
-		Amazon:      "test-amazon-crawler",
 		NumCrawlers: 1,
+		Retry: config.RetryConfig{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			RetryOn:        []string{"blocked"},
+		},
 	}
 	mockProducts := []entities.Product{
 		{
@@ -103,16 +606,463 @@ func TestCrawlerServiceWithCrawlerRunError(t *testing.T) {
 	}
 
 	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Warn", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon"}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[0]).Return(`{"schema_version": 1, "error": {"code": "captcha"}}`, nil)
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	mockCrawler.AssertNumberOfCalls(t, "RunCrawler", 3)
+	assert.Equal(t, RunStats{Failed: 1}, crawlerService.Stats())
+}
+
+func TestCrawlerServiceBatchRetriesReportedFailures(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+		Retry: config.RetryConfig{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			RetryOn:        []string{"blocked"},
+		},
+	}
+	mockProducts := []entities.Product{
+		{ID: uuid.New(), Description: "test-product-1", MaxPrice: 1000, CrawlerName: "amazon"},
+		{ID: uuid.New(), Description: "test-product-2", MaxPrice: 1000, CrawlerName: "amazon"},
+		{ID: uuid.New(), Description: "test-product-3", MaxPrice: 1000, CrawlerName: "amazon"},
+	}
+	crawlerOutputs := func(ctx context.Context, site config.CrawlerSiteConfig, products []entities.Product) map[uuid.UUID]string {
+		outputs := map[uuid.UUID]string{}
+		for _, product := range products {
+			switch product.ID {
+			case mockProducts[0].ID:
+				outputs[product.ID] = `{"schema_version": 1, "price": 1000}`
+			case mockProducts[1].ID:
+				outputs[product.ID] = `{"schema_version": 1, "error": {"code": "captcha"}}`
+			default:
+				outputs[product.ID] = `{"schema_version": 1, "error": {"code": "layout_changed"}}`
+			}
+		}
+		return outputs
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Warn", mock.Anything).Return(nil)
 	mockLogger.On("Error", mock.Anything).Return(nil)
 	mockLogger.On("AddFields", mock.Anything).Return(nil)
-	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything).Return(nil)
-	mockCrawler.On("RunCrawler", mock.Anything, mockProducts[0]).Return("", errors.New("Crawler run error")).Once()
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon", BatchSize: 3}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawlerBatch", mock.Anything, mock.Anything, mockProducts).Return(crawlerOutputs, nil).Once()
+	mockCrawler.On("RunCrawlerBatch", mock.Anything, mock.Anything, mockProducts[1:2]).Return(crawlerOutputs, nil).Twice()
+	mockProductNotificationSvc.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	mockCrawler.AssertNumberOfCalls(t, "RunCrawlerBatch", 3)
+	assert.Equal(t, RunStats{Crawled: 1, Failed: 2}, crawlerService.Stats())
+}
+
+func TestCrawlerServiceBatchRetriesTakeRateLimitTokens(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+		Retry: config.RetryConfig{
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+		},
+	}
+	mockProducts := []entities.Product{
+		{ID: uuid.New(), Description: "test-product-1", MaxPrice: 1000, CrawlerName: "amazon"},
+		{ID: uuid.New(), Description: "test-product-2", MaxPrice: 1200, CrawlerName: "amazon"},
+	}
+	site := config.CrawlerSiteConfig{Name: "amazon", BatchSize: 2, RateLimit: config.RateLimitConfig{DailyBudget: 3}}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Warn", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(site, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawlerBatch", mock.Anything, mock.Anything, mockProducts).Return(map[uuid.UUID]string{}, &entities.CrawlerError{Kind: entities.FailureNetwork}).Once()
+	mockCrawler.On("RunCrawlerBatch", mock.Anything, mock.Anything, mockProducts[:1]).Return(map[uuid.UUID]string{}, &entities.CrawlerError{Kind: entities.FailureNetwork}).Once()
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mockProducts[0].ID, mock.Anything).Return(nil).Once()
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	mockCrawler.AssertNumberOfCalls(t, "RunCrawlerBatch", 2)
+	assert.Equal(t, RunStats{Failed: 1, Skipped: 1}, crawlerService.Stats())
+	mockLogger.AssertCalled(t, "Warn", fmt.Sprintf("%s Limite diário de requisições do crawler amazon atingido, produto ignorado", mockProducts[1].ID))
+}
+
+func TestCrawlerServiceSecondPass(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+		Retry: config.RetryConfig{
+			SecondPass: true,
+		},
+	}
+	mockProducts := []entities.Product{
+		{
+			Description: "test-product-1",
+			MaxPrice:    1000,
+			CrawlerName: "amazon",
+		},
+		{
+			Description: "test-product-2",
+			MaxPrice:    1000,
+			CrawlerName: "kabum",
+		},
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Warn", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon"}, nil)
+	mockRegistry.On("Site", "kabum").Return(config.CrawlerSiteConfig{Name: "kabum"}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[0]).Return("", &entities.CrawlerError{Kind: entities.FailureTimeout}).Once()
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[0]).Return("Product(price=1000, original_price=1500, discount=None, link='http://test-link-1.com')", nil).Once()
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[1]).Return("", &entities.CrawlerError{Kind: entities.FailureNotFound}).Once()
+	mockProductNotificationSvc.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	mockCrawler.AssertNumberOfCalls(t, "RunCrawler", 3)
+	assert.Equal(t, RunStats{Crawled: 1, Failed: 1}, crawlerService.Stats())
+}
+
+func TestCrawlerServiceWithOpenCircuit(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+		CircuitBreaker: config.CircuitBreakerConfig{
+			FailureRatio: 1,
+			MinRequests:  2,
+		},
+	}
+	mockProducts := []entities.Product{}
+	for i := 0; i < 4; i++ {
+		mockProducts = append(mockProducts, entities.Product{
+			Description: fmt.Sprintf("test-product-%d", i),
+			MaxPrice:    1000,
+			CrawlerName: "amazon",
+		})
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Warn", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon"}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mock.Anything).Return("", &entities.CrawlerError{Kind: entities.FailureBlocked})
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	mockCrawler.AssertNumberOfCalls(t, "RunCrawler", 2)
+	assert.Equal(t, RunStats{Failed: 2, Skipped: 2}, crawlerService.Stats())
+	mockProductsRepo.AssertNumberOfCalls(t, "UpdateLastCheckedAt", 2)
+}
+
+func TestCrawlerServiceReportedFailuresOpenCircuit(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+		CircuitBreaker: config.CircuitBreakerConfig{
+			FailureRatio: 1,
+			MinRequests:  2,
+		},
+	}
+	mockProducts := []entities.Product{}
+	for i := 0; i < 4; i++ {
+		mockProducts = append(mockProducts, entities.Product{
+			Description: fmt.Sprintf("test-product-%d", i),
+			MaxPrice:    1000,
+			CrawlerName: "amazon",
+		})
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Warn", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon"}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mock.Anything).Return(`{"schema_version": 1, "error": {"code": "captcha"}}`, nil)
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	mockCrawler.AssertNumberOfCalls(t, "RunCrawler", 2)
+	assert.Equal(t, RunStats{Failed: 2, Skipped: 2}, crawlerService.Stats())
+}
+
+func TestCrawlerServiceReportedBatchFailuresOpenCircuit(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+		CircuitBreaker: config.CircuitBreakerConfig{
+			FailureRatio: 1,
+			MinRequests:  2,
+		},
+	}
+	mockProducts := []entities.Product{}
+	for i := 0; i < 6; i++ {
+		mockProducts = append(mockProducts, entities.Product{
+			ID:          uuid.New(),
+			Description: fmt.Sprintf("test-product-%d", i),
+			MaxPrice:    1000,
+			CrawlerName: "amazon",
+		})
+	}
+	captchas := func(ctx context.Context, site config.CrawlerSiteConfig, products []entities.Product) map[uuid.UUID]string {
+		outputs := map[uuid.UUID]string{}
+		for _, product := range products {
+			outputs[product.ID] = `{"schema_version": 1, "error": {"code": "captcha"}}`
+		}
+		return outputs
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Warn", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon", BatchSize: 2}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawlerBatch", mock.Anything, mock.Anything, mock.Anything).Return(captchas, nil)
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	mockCrawler.AssertNumberOfCalls(t, "RunCrawlerBatch", 2)
+	assert.Equal(t, RunStats{Failed: 4, Skipped: 2}, crawlerService.Stats())
+}
+
+func TestCrawlerServiceSetupErrorsDoNotOpenCircuit(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+		CircuitBreaker: config.CircuitBreakerConfig{
+			FailureRatio: 1,
+			MinRequests:  2,
+		},
+	}
+	mockProducts := []entities.Product{}
+	for i := 0; i < 4; i++ {
+		mockProducts = append(mockProducts, entities.Product{
+			Description: fmt.Sprintf("test-product-%d", i),
+			MaxPrice:    1000,
+			CrawlerName: "amazon",
+		})
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Error", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "amazon").Return(config.CrawlerSiteConfig{Name: "amazon"}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("Env setup error"))
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	mockCrawler.AssertNumberOfCalls(t, "SetupCrawlerEnv", 4)
+	assert.Equal(t, RunStats{Failed: 4}, crawlerService.Stats())
+}
+
+func TestCrawlerServiceSharedLinks(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 2,
+	}
+	mockProducts := []entities.Product{
+		{ID: uuid.New(), UserID: uuid.New(), MaxPrice: 1000, CrawlerName: "kabum", Link: "https://www.kabum.com.br/produto/123456"},
+		{ID: uuid.New(), UserID: uuid.New(), MaxPrice: 1200, CrawlerName: "kabum", Link: "https://www.kabum.com.br/produto/123456?utm_source=telegram"},
+		{ID: uuid.New(), UserID: uuid.New(), MaxPrice: 900, CrawlerName: "kabum", Link: "https://www.kabum.com.br/produto/123456#reviews"},
+	}
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "kabum").Return(config.CrawlerSiteConfig{Name: "kabum", Domains: []string{"kabum.com.br"}}, nil)
+	mockRegistry.On("CrawlerForLink", mock.Anything).Return("kabum", nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, mockProducts[0]).Return(`{"schema_version": 1, "price": 1000}`, nil).Once()
+	mockProductNotificationSvc.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	mockCrawler.AssertNumberOfCalls(t, "RunCrawler", 1)
+	assert.Equal(t, RunStats{Crawled: 1}, crawlerService.Stats())
+	for _, product := range mockProducts {
+		product := product
+		mockProductNotificationSvc.AssertCalled(t, "Execute", mock.Anything, &product, &entities.ProductSearchResult{ProductID: product.ID, UserID: product.UserID, Price: 1000})
+		mockProductsRepo.AssertCalled(t, "UpdateLastCheckedAt", mock.Anything, product.ID, mock.Anything)
+	}
+}
+
+func TestCrawlerServiceDetectsStore(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers: 1,
+	}
+	mockProducts := []entities.Product{
+		{ID: uuid.New(), UserID: uuid.New(), MaxPrice: 1000, Link: "https://produto.mercadolivre.com.br/MLB-1234567890-fone-de-ouvido-_JM"},
+		{ID: uuid.New(), UserID: uuid.New(), MaxPrice: 1000, Link: "https://www.unsupported-store.com/item/1"},
+		{ID: uuid.New(), UserID: uuid.New(), MaxPrice: 1000, CrawlerName: "mercado-livre", Link: "https://www.kabum.com.br/produto/123456"},
+	}
+	crawledProduct := mockProducts[0]
+	crawledProduct.CrawlerName = "mercado-livre"
+	crawledProduct.Link = "https://produto.mercadolivre.com.br/MLB-1234567890"
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("Error", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("Site", "mercado-livre").Return(config.CrawlerSiteConfig{Name: "mercado-livre", Domains: []string{"mercadolivre.com.br"}}, nil)
+	mockRegistry.On("CrawlerForLink", mockProducts[0].Link).Return("mercado-livre", nil)
+	mockRegistry.On("CrawlerForLink", mockProducts[1].Link).Return("", fmt.Errorf("%w: %q", entities.ErrUnsupportedStore, "www.unsupported-store.com"))
+	mockRegistry.On("CrawlerForLink", mockProducts[2].Link).Return("kabum", nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, crawledProduct).Return(`{"schema_version": 1, "price": 900}`, nil).Once()
+	mockProductNotificationSvc.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
+
+	require.NoError(t, err)
+	assert.Equal(t, RunStats{Crawled: 1, Failed: 2}, crawlerService.Stats())
+	mockLogger.AssertCalled(t, "Error", `unsupported store: "www.unsupported-store.com"`)
+	mockLogger.AssertCalled(t, "Error", `link is not of the crawler domains: "https://www.kabum.com.br/produto/123456" is not a link of crawler "mercado-livre"`)
+	mockProductsRepo.AssertCalled(t, "UpdateLastCheckedAt", mock.Anything, mockProducts[0].ID, mock.Anything)
+	mockProductsRepo.AssertCalled(t, "UpdateLastCheckedAt", mock.Anything, mockProducts[1].ID, mock.Anything)
+}
+
+func TestCrawlerServiceFallbackCrawler(t *testing.T) {
+	mockLogger := mocks.NewLoggerContract(t)
+	mockCrawler := mocks.NewCrawler(t)
+	mockProductNotificationSvc := mocks.NewProductNotificationService(t)
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockRegistry := mocks.NewCrawlerRegistry(t)
+	mockProductsRepo := mocks.NewProductsRepository(t)
+
+	parser := crawlerparser.NewRegistry()
+	cfg := config.CrawlerConfig{
+		NumCrawlers:     1,
+		FallbackCrawler: "generic",
+	}
+	mockProducts := []entities.Product{
+		{ID: uuid.New(), UserID: uuid.New(), MaxPrice: 1000, Link: "https://www.unsupported-store.com/item/1"},
+	}
+	crawledProduct := mockProducts[0]
+	crawledProduct.CrawlerName = "generic"
+
+	mockLogger.On("Info", mock.Anything).Return(nil)
+	mockLogger.On("AddFields", mock.Anything).Return(nil)
+	mockRegistry.On("CrawlerForLink", mockProducts[0].Link).Return("", entities.ErrUnsupportedStore)
+	mockRegistry.On("Site", "generic").Return(config.CrawlerSiteConfig{Name: "generic", Type: config.CrawlerTypeNative}, nil)
+	mockCrawler.On("SetupCrawlerEnv", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCrawler.On("RunCrawler", mock.Anything, mock.Anything, crawledProduct).Return(`{"schema_version": 1, "price": 900}`, nil).Once()
+	mockProductNotificationSvc.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepoManager.On("Products").Return(mockProductsRepo)
+	mockProductsRepo.On("UpdateLastCheckedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	crawlerService := NewCrawlerService(parser, &cfg, mockProductNotificationSvc, mockLogger, mockCrawler)
-	err := crawlerService.Execute(mockProducts)
+	crawlerService := NewCrawlerService(parser, &cfg, mockRepoManager, mockProductNotificationSvc, mockLogger, mockCrawler, mockRegistry)
+	err := crawlerService.Execute(context.Background(), mockProducts)
 
 	require.NoError(t, err)
-	mockProductNotificationSvc.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
-	mockCrawler.AssertCalled(t, "SetupCrawlerEnv", mock.Anything, mock.Anything)
-	mockCrawler.AssertCalled(t, "RunCrawler", mock.Anything, mockProducts[0])
+	assert.Equal(t, RunStats{Crawled: 1}, crawlerService.Stats())
 }
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/google/uuid"
+)
+
+// sendEvents sends the opt-in notifications raised by the check when
+// compared with the previous one
+func (p *ProductNotificationService) sendEvents(ctx context.Context, product entities.Product, current entities.ProductSearchResult, productSearchHistory []entities.ProductSearchResult) {
+	if len(p.kinds) == 0 {
+		return
+	}
+	previous, found := previousCheck(productSearchHistory, current)
+
+	if p.kinds[entities.NotificationDelisted] && current.Availability == entities.AvailabilityUnavailable &&
+		(!found || previous.Availability != entities.AvailabilityUnavailable) {
+		p.sendEvent(ctx, product, entities.DelistedNotification{
+			Kind:        entities.NotificationDelisted,
+			Description: product.Description,
+			Link:        product.Link,
+			UserID:      product.UserID.String(),
+		})
+	}
+
+	if !found || !current.IsPriceValid() || current.Availability.IsOutOfStock() {
+		return
+	}
+
+	if p.kinds[entities.NotificationBackInStock] && previous.Availability.IsOutOfStock() {
+		p.sendEvent(ctx, product, entities.BackInStockNotification{
+			Kind:         entities.NotificationBackInStock,
+			Description:  product.Description,
+			Price:        float64(current.Price),
+			Availability: current.Availability,
+			Link:         product.Link,
+			UserID:       product.UserID.String(),
+		})
+	}
+
+	if p.kinds[entities.NotificationPriceIncrease] && previous.IsPriceValid() {
+		increase := float64(current.Price-previous.Price) / float64(previous.Price)
+		if increase > 0 && increase*100 >= p.priceIncreaseThreshold {
+			p.sendEvent(ctx, product, entities.PriceIncreaseNotification{
+				Kind:          entities.NotificationPriceIncrease,
+				Description:   product.Description,
+				Price:         float64(current.Price),
+				PreviousPrice: float64(previous.Price),
+				Increase:      fmt.Sprintf("%.2f", increase),
+				Link:          product.Link,
+				UserID:        product.UserID.String(),
+			})
+		}
+	}
+}
+
+func (p *ProductNotificationService) sendEvent(ctx context.Context, product entities.Product, payload interface{}) {
+	if err := p.queueManager.SendMessage(ctx, payload); err != nil {
+		p.logger.Error(fmt.Sprintf("%s: Erro ao enviar notificação", product.ID))
+		p.logger.Error(err.Error())
+	}
+}
+
+// previousCheck latest check of the history other than the current one
+func previousCheck(productSearchHistory []entities.ProductSearchResult, current entities.ProductSearchResult) (entities.ProductSearchResult, bool) {
+	var previous entities.ProductSearchResult
+	found := false
+	for _, check := range productSearchHistory {
+		if current.ID != uuid.Nil && check.ID == current.ID {
+			continue
+		}
+		if !found || check.CreatedAt.After(previous.CreatedAt) {
+			previous = check
+			found = true
+		}
+	}
+
+	return previous, found
+}
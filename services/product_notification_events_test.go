@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	mocks "github.com/JoaoLeal92/product-monitor-orchestrator/contracts/mocks"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductNotificationEvents(t *testing.T) {
+	checkedAt := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	product := entities.Product{
+		Description: "test-product",
+		MaxPrice:    1000,
+		Link:        "http://test-link.com",
+	}
+
+	tests := map[string]struct {
+		kinds           []string
+		history         []entities.ProductSearchResult
+		current         entities.ProductSearchResult
+		expectedPayload interface{}
+	}{
+		"back-in-stock": {
+			[]string{"back_in_stock"},
+			[]entities.ProductSearchResult{
+				{Price: 1500, Availability: entities.AvailabilityInStock, CreatedAt: checkedAt.Add(-2 * time.Hour)},
+				{Availability: entities.AvailabilityOutOfStock, CreatedAt: checkedAt.Add(-time.Hour)},
+			},
+			entities.ProductSearchResult{Price: 1500, Availability: entities.AvailabilityInStock},
+			entities.BackInStockNotification{
+				Kind:         entities.NotificationBackInStock,
+				Description:  "test-product",
+				Price:        1500,
+				Availability: entities.AvailabilityInStock,
+				Link:         "http://test-link.com",
+				UserID:       product.UserID.String(),
+			},
+		},
+		"price-increase": {
+			[]string{"price_increase"},
+			[]entities.ProductSearchResult{
+				{Price: 1100, CreatedAt: checkedAt.Add(-time.Hour)},
+				{Price: 1300, CreatedAt: checkedAt.Add(-2 * time.Hour)},
+			},
+			entities.ProductSearchResult{Price: 1320},
+			entities.PriceIncreaseNotification{
+				Kind:          entities.NotificationPriceIncrease,
+				Description:   "test-product",
+				Price:         1320,
+				PreviousPrice: 1100,
+				Increase:      "0.20",
+				Link:          "http://test-link.com",
+				UserID:        product.UserID.String(),
+			},
+		},
+		"price-increase-below-threshold": {
+			[]string{"price_increase"},
+			[]entities.ProductSearchResult{{Price: 1200, CreatedAt: checkedAt.Add(-time.Hour)}},
+			entities.ProductSearchResult{Price: 1320},
+			nil,
+		},
+		"delisted": {
+			[]string{"delisted"},
+			[]entities.ProductSearchResult{{Price: 1200, CreatedAt: checkedAt.Add(-time.Hour)}},
+			entities.ProductSearchResult{Availability: entities.AvailabilityUnavailable},
+			entities.DelistedNotification{
+				Kind:        entities.NotificationDelisted,
+				Description: "test-product",
+				Link:        "http://test-link.com",
+				UserID:      product.UserID.String(),
+			},
+		},
+		"already-delisted": {
+			[]string{"delisted"},
+			[]entities.ProductSearchResult{{Availability: entities.AvailabilityUnavailable, CreatedAt: checkedAt.Add(-time.Hour)}},
+			entities.ProductSearchResult{Availability: entities.AvailabilityUnavailable},
+			nil,
+		},
+		"not-opted-in": {
+			[]string{"delisted"},
+			[]entities.ProductSearchResult{{Availability: entities.AvailabilityOutOfStock, CreatedAt: checkedAt.Add(-time.Hour)}},
+			entities.ProductSearchResult{Price: 1500, Availability: entities.AvailabilityInStock},
+			nil,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mockRepoManager := mocks.NewRepoManager(t)
+			mockProductSearcHistoryRepo := mocks.NewProductSearchHistoryRepository(t)
+			mockQueueManager := mocks.NewQueueManager(t)
+			mockLogger := mocks.NewLoggerContract(t)
+
+			mockRepoManager.On("ProductSearchHistory").Return(mockProductSearcHistoryRepo)
+			mockProductSearcHistoryRepo.On("InsertNewHistory", mock.Anything, mock.Anything).Return(nil)
+			mockProductSearcHistoryRepo.On("GetHistoryByProductID", mock.Anything, mock.Anything).Return(testData.history, nil)
+			mockLogger.On("Info", mock.Anything).Return(nil)
+			if testData.expectedPayload != nil {
+				mockQueueManager.On("SendMessage", mock.Anything, testData.expectedPayload).Return(nil).Once()
+			}
+
+			cfg := config.NotificationConfig{Kinds: testData.kinds, PriceIncreaseThreshold: 15}
+			productNotificationService, err := NewProductNotificationService(&cfg, mockRepoManager, mockLogger, mockQueueManager)
+			require.NoError(t, err)
+			current := testData.current
+			err = productNotificationService.Execute(context.Background(), &product, &current)
+
+			require.NoError(t, err)
+			if testData.expectedPayload == nil {
+				mockQueueManager.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestPreviousCheckSkipsCurrent(t *testing.T) {
+	current := entities.ProductSearchResult{ID: uuid.New(), Price: 1000, CreatedAt: time.Now()}
+	history := []entities.ProductSearchResult{
+		{ID: uuid.New(), Price: 900, CreatedAt: current.CreatedAt.Add(-time.Hour)},
+		current,
+	}
+
+	previous, found := previousCheck(history, current)
+
+	require.True(t, found)
+	assert.Equal(t, 900, previous.Price)
+}
+
+func TestNotificationConfigValidation(t *testing.T) {
+	tests := map[string]config.NotificationConfig{
+		"unknown-kind":       {Kinds: []string{"price_drop"}},
+		"below-max-price":    {Kinds: []string{"below_max_price"}},
+		"negative-threshold": {Kinds: []string{"price_increase"}, PriceIncreaseThreshold: -5},
+		"missing-threshold":  {Kinds: []string{"price_increase"}},
+	}
+
+	for testName, cfg := range tests {
+		t.Run(testName, func(t *testing.T) {
+			_, err := NewProductNotificationService(&cfg, nil, nil, nil)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -1,18 +1,22 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
 
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
 	"github.com/JoaoLeal92/product-monitor-orchestrator/contracts"
 	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
 )
 
 type ProductNotificationService struct {
-	db           contracts.RepoManager
-	logger       contracts.LoggerContract
-	queueManager contracts.QueueManager
+	db                     contracts.RepoManager
+	logger                 contracts.LoggerContract
+	queueManager           contracts.QueueManager
+	kinds                  map[entities.NotificationKind]bool
+	priceIncreaseThreshold float64
 }
 
 type averageProductData struct {
@@ -20,36 +24,79 @@ type averageProductData struct {
 	avgDiscount string
 }
 
-func NewProductNotificationService(db contracts.RepoManager, logger contracts.LoggerContract, queueManager contracts.QueueManager) *ProductNotificationService {
-	return &ProductNotificationService{
-		db:           db,
-		logger:       logger,
-		queueManager: queueManager,
+// NewProductNotificationService instantiates the service, validating the
+// opt-in notification kinds
+func NewProductNotificationService(cfg *config.NotificationConfig, db contracts.RepoManager, logger contracts.LoggerContract, queueManager contracts.QueueManager) (*ProductNotificationService, error) {
+	kinds := make(map[entities.NotificationKind]bool, len(cfg.Kinds))
+	for _, value := range cfg.Kinds {
+		kind, err := entities.ParseNotificationKind(value)
+		if err != nil {
+			return &ProductNotificationService{}, err
+		}
+		kinds[kind] = true
+	}
+	if cfg.PriceIncreaseThreshold < 0 {
+		return &ProductNotificationService{}, fmt.Errorf("negative price-increase-threshold %v", cfg.PriceIncreaseThreshold)
+	}
+	if kinds[entities.NotificationPriceIncrease] && cfg.PriceIncreaseThreshold == 0 {
+		return &ProductNotificationService{}, errors.New("price_increase notifications need a price-increase-threshold")
 	}
+
+	return &ProductNotificationService{
+		db:                     db,
+		logger:                 logger,
+		queueManager:           queueManager,
+		kinds:                  kinds,
+		priceIncreaseThreshold: cfg.PriceIncreaseThreshold,
+	}, nil
 }
 
-func (p *ProductNotificationService) Execute(product *entities.Product, productSearchResult *entities.ProductSearchResult) error {
-	if !productSearchResult.IsPriceValid() {
+func (p *ProductNotificationService) Execute(ctx context.Context, product *entities.Product, productSearchResult *entities.ProductSearchResult) error {
+	if !productSearchResult.IsValid() {
 		p.logger.Info("Preço inválido")
 		return errors.New("invalid price result (<0)")
 	}
 
-	if err := p.db.ProductSearchHistory().InsertNewHistory(productSearchResult); err != nil {
+	if err := p.db.ProductSearchHistory().InsertNewHistory(ctx, productSearchResult); err != nil {
 		return err
 	}
 
-	if !product.IsBelowMaxPrice(productSearchResult.Price) {
-		p.logger.Info("Preço acima do desejado")
+	outOfStock := productSearchResult.Availability.IsOutOfStock()
+
+	var productSearchHistory []entities.ProductSearchResult
+	historyLoaded := false
+	if len(p.kinds) > 0 || (!outOfStock && needsHistory(product.AlertRules())) {
+		var err error
+		productSearchHistory, err = p.db.ProductSearchHistory().GetHistoryByProductID(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+		historyLoaded = true
+		p.sendEvents(ctx, *product, *productSearchResult, productSearchHistory)
+	}
+
+	if outOfStock {
+		p.logger.Info("Produto fora de estoque")
 		return nil
 	}
 
-	productSearchHistory, err := p.db.ProductSearchHistory().GetHistoryByProductID(product.ID)
-	if err != nil {
-		return err
+	matchedRules := p.matchRules(*product, *productSearchResult, productSearchHistory)
+	if len(matchedRules) == 0 {
+		p.logger.Info("Nenhuma regra de alerta atendida")
+		return nil
+	}
+
+	if !historyLoaded {
+		var err error
+		productSearchHistory, err = p.db.ProductSearchHistory().GetHistoryByProductID(ctx, product.ID)
+		if err != nil {
+			return err
+		}
 	}
 	avgData := p.getAverageProductData(productSearchHistory, productSearchResult.Price)
 	queuePayload := p.formatQueuePayload(*product, *productSearchResult, avgData)
-	p.queueManager.SendMessage(queuePayload)
+	queuePayload.MatchedRules = matchedRules
+	p.sendEvent(ctx, *product, queuePayload)
 
 	return nil
 }
@@ -64,14 +111,21 @@ func (p *ProductNotificationService) getAverageProductData(productHistory []enti
 	}
 }
 
+// getProductAvgPrice average of the prices seen, leaving out the checks where
+// the product was out of stock without a price
 func (p *ProductNotificationService) getProductAvgPrice(productHistory []entities.ProductSearchResult, currentPrice int) float64 {
 	var sum float64 = 0
+	count := 0
 	for _, product := range productHistory {
+		if !product.IsPriceValid() {
+			continue
+		}
 		sum += float64(product.Price) / 100
+		count++
 	}
 	sum += float64(currentPrice)
 
-	avg := sum / float64(len(productHistory)+1)
+	avg := sum / float64(count+1)
 	roundAvg := math.Round(avg*100) / 100
 
 	return roundAvg
@@ -90,12 +144,14 @@ func (p *ProductNotificationService) getAvgDiscount(avgPrice float64, currentPri
 
 func (p *ProductNotificationService) formatQueuePayload(product entities.Product, productSearchResult entities.ProductSearchResult, avgProductData averageProductData) entities.ProductNotification {
 	return entities.ProductNotification{
-		Description: product.Description,
-		Price:       float64(productSearchResult.Price),
-		AvgPrice:    avgProductData.avgPrice,
-		Discount:    productSearchResult.Discount,
-		AvgDiscount: avgProductData.avgDiscount,
-		Link:        product.Link,
-		UserID:      product.UserID.String(),
+		Kind:         entities.NotificationBelowMaxPrice,
+		Description:  product.Description,
+		Price:        float64(productSearchResult.Price),
+		AvgPrice:     avgProductData.avgPrice,
+		Discount:     productSearchResult.Discount,
+		Availability: productSearchResult.Availability,
+		AvgDiscount:  avgProductData.avgDiscount,
+		Link:         product.Link,
+		UserID:       product.UserID.String(),
 	}
 }
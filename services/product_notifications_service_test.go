@@ -1,9 +1,11 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"testing"
 
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
 	mocks "github.com/JoaoLeal92/product-monitor-orchestrator/contracts/mocks"
 	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
 	"github.com/stretchr/testify/assert"
@@ -18,9 +20,9 @@ func TestProductNotificationService(t *testing.T) {
 	mockLogger := mocks.NewLoggerContract(t)
 
 	mockRepoManager.On("ProductSearchHistory").Return(mockProductSearcHistoryRepo).Twice()
-	mockProductSearcHistoryRepo.On("InsertNewHistory", mock.Anything).Return(nil)
-	mockQueueManager.On("SendMessage", mock.Anything).Return(nil)
-	mockProductSearcHistoryRepo.On("GetHistoryByProductID", mock.Anything).Return([]entities.ProductSearchResult{
+	mockProductSearcHistoryRepo.On("InsertNewHistory", mock.Anything, mock.Anything).Return(nil)
+	mockQueueManager.On("SendMessage", mock.Anything, mock.Anything).Return(nil)
+	mockProductSearcHistoryRepo.On("GetHistoryByProductID", mock.Anything, mock.Anything).Return([]entities.ProductSearchResult{
 		{
 			Price: 100000,
 		},
@@ -29,7 +31,8 @@ func TestProductNotificationService(t *testing.T) {
 		},
 	}, nil)
 
-	productNotificationService := NewProductNotificationService(mockRepoManager, mockLogger, mockQueueManager)
+	productNotificationService, err := NewProductNotificationService(&config.NotificationConfig{}, mockRepoManager, mockLogger, mockQueueManager)
+	require.NoError(t, err)
 	productSearchResultStub := entities.ProductSearchResult{
 		Price: 999,
 	}
@@ -38,19 +41,51 @@ func TestProductNotificationService(t *testing.T) {
 		MaxPrice:    1000,
 	}
 	expectedProductNotification := entities.ProductNotification{
+		Kind:        entities.NotificationBelowMaxPrice,
 		Description: "test-product",
 		Price:       999,
 		AvgPrice:    1000,
 		AvgDiscount: "0.99",
 		UserID:      product.UserID.String(),
+		MatchedRules: entities.AlertRules{
+			{Type: entities.RuleTargetPrice, Target: 1000},
+		},
 	}
-	err := productNotificationService.Execute(&product, &productSearchResultStub)
+	err = productNotificationService.Execute(context.Background(), &product, &productSearchResultStub)
 
 	require.NoError(t, err)
 	mockRepoManager.AssertNumberOfCalls(t, "ProductSearchHistory", 2)
-	mockProductSearcHistoryRepo.AssertCalled(t, "InsertNewHistory", mock.Anything)
-	mockProductSearcHistoryRepo.AssertCalled(t, "GetHistoryByProductID", mock.Anything)
-	mockQueueManager.AssertCalled(t, "SendMessage", expectedProductNotification)
+	mockProductSearcHistoryRepo.AssertCalled(t, "InsertNewHistory", mock.Anything, mock.Anything)
+	mockProductSearcHistoryRepo.AssertCalled(t, "GetHistoryByProductID", mock.Anything, mock.Anything)
+	mockQueueManager.AssertCalled(t, "SendMessage", mock.Anything, expectedProductNotification)
+}
+
+func TestErrorOnSendNotification(t *testing.T) {
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockProductSearcHistoryRepo := mocks.NewProductSearchHistoryRepository(t)
+	mockQueueManager := mocks.NewQueueManager(t)
+	mockLogger := mocks.NewLoggerContract(t)
+
+	mockRepoManager.On("ProductSearchHistory").Return(mockProductSearcHistoryRepo).Twice()
+	mockProductSearcHistoryRepo.On("InsertNewHistory", mock.Anything, mock.Anything).Return(nil)
+	mockProductSearcHistoryRepo.On("GetHistoryByProductID", mock.Anything, mock.Anything).Return([]entities.ProductSearchResult{}, nil)
+	mockQueueManager.On("SendMessage", mock.Anything, mock.Anything).Return(errors.New("queue unavailable"))
+	mockLogger.On("Error", mock.Anything).Return(nil)
+
+	productNotificationService, err := NewProductNotificationService(&config.NotificationConfig{}, mockRepoManager, mockLogger, mockQueueManager)
+	require.NoError(t, err)
+	productSearchResultStub := entities.ProductSearchResult{
+		Price: 999,
+	}
+	product := entities.Product{
+		Description: "test-product",
+		MaxPrice:    1000,
+	}
+	err = productNotificationService.Execute(context.Background(), &product, &productSearchResultStub)
+
+	require.NoError(t, err)
+	mockQueueManager.AssertNumberOfCalls(t, "SendMessage", 1)
+	mockLogger.AssertCalled(t, "Error", "queue unavailable")
 }
 
 func TestInvalidProductSearchResult(t *testing.T) {
@@ -60,16 +95,17 @@ func TestInvalidProductSearchResult(t *testing.T) {
 	mockLogger := mocks.NewLoggerContract(t)
 	mockLogger.On("Info", mock.Anything).Return(nil)
 
-	productNotificationService := NewProductNotificationService(mockRepoManager, mockLogger, mockQueueManager)
+	productNotificationService, err := NewProductNotificationService(&config.NotificationConfig{}, mockRepoManager, mockLogger, mockQueueManager)
+	require.NoError(t, err)
 	productSearchResultStub := entities.ProductSearchResult{}
 	product := entities.Product{}
-	err := productNotificationService.Execute(&product, &productSearchResultStub)
+	err = productNotificationService.Execute(context.Background(), &product, &productSearchResultStub)
 
 	require.Error(t, err)
 	assert.Equal(t, err.Error(), "invalid price result (<0)")
 	mockRepoManager.AssertNotCalled(t, "ProductSearchHistory")
-	mockProductSearcHistoryRepo.AssertNotCalled(t, "InsertNewHistory", mock.Anything)
-	mockQueueManager.AssertNotCalled(t, "SendMessage", mock.Anything)
+	mockProductSearcHistoryRepo.AssertNotCalled(t, "InsertNewHistory", mock.Anything, mock.Anything)
+	mockQueueManager.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
 }
 
 func TestErrorOnDbInsert(t *testing.T) {
@@ -79,20 +115,21 @@ func TestErrorOnDbInsert(t *testing.T) {
 	mockLogger := mocks.NewLoggerContract(t)
 
 	mockRepoManager.On("ProductSearchHistory").Return(mockProductSearcHistoryRepo)
-	mockProductSearcHistoryRepo.On("InsertNewHistory", mock.Anything).Return(errors.New("db error"))
+	mockProductSearcHistoryRepo.On("InsertNewHistory", mock.Anything, mock.Anything).Return(errors.New("db error"))
 
-	productNotificationService := NewProductNotificationService(mockRepoManager, mockLogger, mockQueueManager)
+	productNotificationService, err := NewProductNotificationService(&config.NotificationConfig{}, mockRepoManager, mockLogger, mockQueueManager)
+	require.NoError(t, err)
 	productSearchResultStub := entities.ProductSearchResult{
 		Price: 999,
 	}
 	product := entities.Product{}
-	err := productNotificationService.Execute(&product, &productSearchResultStub)
+	err = productNotificationService.Execute(context.Background(), &product, &productSearchResultStub)
 
 	require.Error(t, err)
 	assert.Equal(t, err.Error(), "db error")
 	mockRepoManager.AssertCalled(t, "ProductSearchHistory")
-	mockProductSearcHistoryRepo.AssertCalled(t, "InsertNewHistory", &productSearchResultStub)
-	mockQueueManager.AssertNotCalled(t, "SendMessage", mock.Anything)
+	mockProductSearcHistoryRepo.AssertCalled(t, "InsertNewHistory", mock.Anything, &productSearchResultStub)
+	mockQueueManager.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
 }
 
 func TestProductWithPriceAboveMaxPrice(t *testing.T) {
@@ -102,10 +139,11 @@ func TestProductWithPriceAboveMaxPrice(t *testing.T) {
 	mockLogger := mocks.NewLoggerContract(t)
 
 	mockRepoManager.On("ProductSearchHistory").Return(mockProductSearcHistoryRepo)
-	mockProductSearcHistoryRepo.On("InsertNewHistory", mock.Anything).Return(nil)
+	mockProductSearcHistoryRepo.On("InsertNewHistory", mock.Anything, mock.Anything).Return(nil)
 	mockLogger.On("Info", mock.Anything).Return(nil)
 
-	productNotificationService := NewProductNotificationService(mockRepoManager, mockLogger, mockQueueManager)
+	productNotificationService, err := NewProductNotificationService(&config.NotificationConfig{}, mockRepoManager, mockLogger, mockQueueManager)
+	require.NoError(t, err)
 	productSearchResultStub := entities.ProductSearchResult{
 		Price: 1001,
 	}
@@ -113,10 +151,85 @@ func TestProductWithPriceAboveMaxPrice(t *testing.T) {
 		Description: "test-product",
 		MaxPrice:    1000,
 	}
-	err := productNotificationService.Execute(&product, &productSearchResultStub)
+	err = productNotificationService.Execute(context.Background(), &product, &productSearchResultStub)
 
 	require.NoError(t, err)
 	mockRepoManager.AssertCalled(t, "ProductSearchHistory")
-	mockProductSearcHistoryRepo.AssertCalled(t, "InsertNewHistory", &productSearchResultStub)
-	mockQueueManager.AssertNotCalled(t, "SendMessage", mock.Anything)
+	mockProductSearcHistoryRepo.AssertCalled(t, "InsertNewHistory", mock.Anything, &productSearchResultStub)
+	mockQueueManager.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
+}
+
+func TestOutOfStockProductSearchResult(t *testing.T) {
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockProductSearcHistoryRepo := mocks.NewProductSearchHistoryRepository(t)
+	mockQueueManager := mocks.NewQueueManager(t)
+	mockLogger := mocks.NewLoggerContract(t)
+
+	mockRepoManager.On("ProductSearchHistory").Return(mockProductSearcHistoryRepo)
+	mockProductSearcHistoryRepo.On("InsertNewHistory", mock.Anything, mock.Anything).Return(nil)
+	mockLogger.On("Info", mock.Anything).Return(nil)
+
+	productNotificationService, err := NewProductNotificationService(&config.NotificationConfig{}, mockRepoManager, mockLogger, mockQueueManager)
+	require.NoError(t, err)
+	productSearchResultStub := entities.ProductSearchResult{
+		Availability: entities.AvailabilityOutOfStock,
+	}
+	product := entities.Product{
+		Description: "test-product",
+		MaxPrice:    1000,
+	}
+	err = productNotificationService.Execute(context.Background(), &product, &productSearchResultStub)
+
+	require.NoError(t, err)
+	mockProductSearcHistoryRepo.AssertCalled(t, "InsertNewHistory", mock.Anything, &productSearchResultStub)
+	mockQueueManager.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
+}
+
+func TestAvgPriceIgnoresOutOfStockHistory(t *testing.T) {
+	mockRepoManager := mocks.NewRepoManager(t)
+	mockProductSearcHistoryRepo := mocks.NewProductSearchHistoryRepository(t)
+	mockQueueManager := mocks.NewQueueManager(t)
+	mockLogger := mocks.NewLoggerContract(t)
+
+	mockRepoManager.On("ProductSearchHistory").Return(mockProductSearcHistoryRepo).Twice()
+	mockProductSearcHistoryRepo.On("InsertNewHistory", mock.Anything, mock.Anything).Return(nil)
+	mockQueueManager.On("SendMessage", mock.Anything, mock.Anything).Return(nil)
+	mockProductSearcHistoryRepo.On("GetHistoryByProductID", mock.Anything, mock.Anything).Return([]entities.ProductSearchResult{
+		{
+			Price: 100000,
+		},
+		{
+			Availability: entities.AvailabilityOutOfStock,
+		},
+		{
+			Price: 100100,
+		},
+	}, nil)
+
+	productNotificationService, err := NewProductNotificationService(&config.NotificationConfig{}, mockRepoManager, mockLogger, mockQueueManager)
+	require.NoError(t, err)
+	productSearchResultStub := entities.ProductSearchResult{
+		Price:        999,
+		Availability: entities.AvailabilityInStock,
+	}
+	product := entities.Product{
+		Description: "test-product",
+		MaxPrice:    1000,
+	}
+	expectedProductNotification := entities.ProductNotification{
+		Kind:         entities.NotificationBelowMaxPrice,
+		Description:  "test-product",
+		Price:        999,
+		AvgPrice:     1000,
+		AvgDiscount:  "0.99",
+		UserID:       product.UserID.String(),
+		Availability: entities.AvailabilityInStock,
+		MatchedRules: entities.AlertRules{
+			{Type: entities.RuleTargetPrice, Target: 1000},
+		},
+	}
+	err = productNotificationService.Execute(context.Background(), &product, &productSearchResultStub)
+
+	require.NoError(t, err)
+	mockQueueManager.AssertCalled(t, "SendMessage", mock.Anything, expectedProductNotification)
 }
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+)
+
+// rateLimiter enforces the politeness quotas of each crawler, keyed by the
+// crawler name. Crawlers without quotas are not limited. Quotas are kept in
+// memory and are not shared between processes, so the daily budget starts
+// over whenever the orchestrator starts
+type rateLimiter struct {
+	mu       sync.Mutex
+	limiters map[string]*siteLimiter
+}
+
+// siteLimiter quotas of a single crawler: a token bucket, a minimum spacing
+// with jitter between requests and a daily request budget
+type siteLimiter struct {
+	mu          sync.Mutex
+	cfg         config.RateLimitConfig
+	rand        *rand.Rand
+	tokens      float64
+	updatedAt   time.Time
+	nextAllowed time.Time
+	day         string
+	usedToday   int
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		limiters: make(map[string]*siteLimiter),
+	}
+}
+
+func newSiteLimiter(cfg config.RateLimitConfig) *siteLimiter {
+	if cfg.Burst < 1 {
+		cfg.Burst = 1
+	}
+
+	return &siteLimiter{
+		cfg:    cfg,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		tokens: float64(cfg.Burst),
+	}
+}
+
+// Reserve books the next request of the crawler and returns how long the
+// caller has to wait before making it. It fails right away with
+// ErrDailyBudgetExhausted when the crawler has no requests left for the day
+func (r *rateLimiter) Reserve(site config.CrawlerSiteConfig) (time.Duration, error) {
+	if site.RateLimit == (config.RateLimitConfig{}) {
+		return 0, nil
+	}
+
+	return r.siteLimiter(site).reserve(time.Now())
+}
+
+func (r *rateLimiter) siteLimiter(site config.CrawlerSiteConfig) *siteLimiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	limiter, ok := r.limiters[site.Name]
+	if !ok {
+		limiter = newSiteLimiter(site.RateLimit)
+		r.limiters[site.Name] = limiter
+	}
+
+	return limiter
+}
+
+// reserve books the next request slot and returns how long the caller has to
+// wait for it
+func (l *siteLimiter) reserve(now time.Time) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	start := now
+	if l.cfg.RequestsPerSecond > 0 {
+		l.refill(now)
+		l.tokens--
+		if l.tokens < 0 {
+			start = now.Add(time.Duration(-l.tokens / l.cfg.RequestsPerSecond * float64(time.Second)))
+		}
+	}
+	if start.Before(l.nextAllowed) {
+		start = l.nextAllowed
+	}
+
+	if l.cfg.DailyBudget > 0 {
+		day := start.Format("2006-01-02")
+		if day != l.day {
+			l.day = day
+			l.usedToday = 0
+		}
+		if l.usedToday >= l.cfg.DailyBudget {
+			if l.cfg.RequestsPerSecond > 0 {
+				l.tokens++
+			}
+			return 0, entities.ErrDailyBudgetExhausted
+		}
+		l.usedToday++
+	}
+
+	l.nextAllowed = start.Add(l.spacing())
+
+	return start.Sub(now), nil
+}
+
+func (l *siteLimiter) refill(now time.Time) {
+	if !l.updatedAt.IsZero() {
+		l.tokens += now.Sub(l.updatedAt).Seconds() * l.cfg.RequestsPerSecond
+		if l.tokens > float64(l.cfg.Burst) {
+			l.tokens = float64(l.cfg.Burst)
+		}
+	}
+	l.updatedAt = now
+}
+
+func (l *siteLimiter) spacing() time.Duration {
+	spacing := l.cfg.MinSpacing
+	if l.cfg.Jitter > 0 {
+		spacing += time.Duration(l.rand.Int63n(int64(l.cfg.Jitter)))
+	}
+
+	return spacing
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSiteLimiterReserve(t *testing.T) {
+	start := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		cfg            config.RateLimitConfig
+		requestTimes   []time.Time
+		expectedDelays []time.Duration
+	}{
+		"token-bucket": {
+			config.RateLimitConfig{RequestsPerSecond: 0.5, Burst: 2},
+			[]time.Time{start, start, start, start.Add(10 * time.Second)},
+			[]time.Duration{0, 0, 2 * time.Second, 0},
+		},
+		"min-spacing": {
+			config.RateLimitConfig{MinSpacing: 3 * time.Second},
+			[]time.Time{start, start.Add(time.Second), start.Add(10 * time.Second)},
+			[]time.Duration{0, 2 * time.Second, 0},
+		},
+		"token-bucket-and-min-spacing": {
+			config.RateLimitConfig{RequestsPerSecond: 1, Burst: 1, MinSpacing: 5 * time.Second},
+			[]time.Time{start, start},
+			[]time.Duration{0, 5 * time.Second},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			limiter := newSiteLimiter(testData.cfg)
+			for i, requestTime := range testData.requestTimes {
+				delay, err := limiter.reserve(requestTime)
+				require.NoError(t, err)
+				assert.Equal(t, testData.expectedDelays[i], delay, "request %d", i)
+			}
+		})
+	}
+}
+
+func TestSiteLimiterDailyBudget(t *testing.T) {
+	start := time.Date(2022, 5, 10, 23, 0, 0, 0, time.UTC)
+	limiter := newSiteLimiter(config.RateLimitConfig{DailyBudget: 2})
+
+	_, err := limiter.reserve(start)
+	require.NoError(t, err)
+	_, err = limiter.reserve(start)
+	require.NoError(t, err)
+	_, err = limiter.reserve(start)
+	assert.ErrorIs(t, err, entities.ErrDailyBudgetExhausted)
+
+	_, err = limiter.reserve(start.Add(2 * time.Hour))
+	assert.NoError(t, err)
+}
+
+func TestSiteLimiterJitter(t *testing.T) {
+	start := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+	limiter := newSiteLimiter(config.RateLimitConfig{MinSpacing: time.Second, Jitter: time.Second})
+
+	_, err := limiter.reserve(start)
+	require.NoError(t, err)
+	delay, err := limiter.reserve(start)
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, delay, time.Second)
+	assert.Less(t, delay, 2*time.Second)
+}
+
+func TestRateLimiterWithoutQuotas(t *testing.T) {
+	limiter := newRateLimiter()
+	amazon := config.CrawlerSiteConfig{Name: "amazon", RateLimit: config.RateLimitConfig{DailyBudget: 1}}
+	kabum := config.CrawlerSiteConfig{Name: "kabum"}
+
+	for i := 0; i < 2; i++ {
+		delay, err := limiter.Reserve(kabum)
+		assert.NoError(t, err)
+		assert.Zero(t, delay)
+	}
+	_, err := limiter.Reserve(amazon)
+	assert.NoError(t, err)
+	_, err = limiter.Reserve(amazon)
+	assert.ErrorIs(t, err, entities.ErrDailyBudgetExhausted)
+}
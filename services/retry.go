@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+)
+
+const (
+	defaultInitialBackoff = 2 * time.Second
+	defaultMaxBackoff     = time.Minute
+)
+
+// defaultRetryOn failure kinds retried when the config does not list any
+var defaultRetryOn = []string{string(entities.FailureNetwork), string(entities.FailureTimeout)}
+
+// retryPolicy how many times and how often a failed product is crawled again
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	retryOn        map[entities.FailureKind]bool
+}
+
+func newRetryPolicy(defaults config.RetryConfig, site config.RetryConfig) retryPolicy {
+	cfg := defaults
+	if site.MaxAttempts > 0 {
+		cfg.MaxAttempts = site.MaxAttempts
+	}
+	if site.InitialBackoff > 0 {
+		cfg.InitialBackoff = site.InitialBackoff
+	}
+	if site.MaxBackoff > 0 {
+		cfg.MaxBackoff = site.MaxBackoff
+	}
+	if len(site.RetryOn) > 0 {
+		cfg.RetryOn = site.RetryOn
+	}
+
+	policy := retryPolicy{
+		maxAttempts:    atLeastOne(cfg.MaxAttempts),
+		initialBackoff: cfg.InitialBackoff,
+		maxBackoff:     cfg.MaxBackoff,
+		retryOn:        make(map[entities.FailureKind]bool),
+	}
+	if policy.initialBackoff <= 0 {
+		policy.initialBackoff = defaultInitialBackoff
+	}
+	if policy.maxBackoff <= 0 {
+		policy.maxBackoff = defaultMaxBackoff
+	}
+
+	retryOn := cfg.RetryOn
+	if len(retryOn) == 0 {
+		retryOn = defaultRetryOn
+	}
+	for _, kind := range retryOn {
+		policy.retryOn[entities.ParseFailureKind(kind)] = true
+	}
+
+	return policy
+}
+
+// retryable whether the failure is worth another attempt. Products skipped for
+// the daily budget or an open circuit breaker, or whose link is not of their
+// crawler, never are
+func (p retryPolicy) retryable(err error) bool {
+	if errors.Is(err, entities.ErrDailyBudgetExhausted) || errors.Is(err, entities.ErrCircuitOpen) || errors.Is(err, entities.ErrLinkNotOfCrawler) {
+		return false
+	}
+
+	return p.retryOn[entities.FailureKindOf(err)]
+}
+
+// backoff delay before the attempt following the given one: exponential,
+// capped at maxBackoff, with a random part of up to half of it
+func (p retryPolicy) backoff(attempt int, random float64) time.Duration {
+	delay := p.initialBackoff
+	for i := 1; i < attempt && delay < p.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.maxBackoff {
+		delay = p.maxBackoff
+	}
+
+	return delay/2 + time.Duration(random*float64(delay/2))
+}
+
+// delay wait before the attempt following the given one, at least as long as
+// the website asked for in the failure
+func (p retryPolicy) delay(err error, attempt int, random float64) time.Duration {
+	delay := p.backoff(attempt, random)
+	if retryAfter := entities.RetryAfterOf(err); retryAfter > delay {
+		return retryAfter
+	}
+
+	return delay
+}
+
+// retry calls attempt until it succeeds, fails with a failure that is not
+// retryable, runs out of attempts or ctx is done, returning its last error.
+// The dispatcher slot is given back during the backoff
+func (c *CrawlerService) retry(ctx context.Context, policy retryPolicy, label string, slot *jobSlot, attempt func() error) error {
+	for n := 1; ; n++ {
+		err := attempt()
+		if err == nil || n >= policy.maxAttempts || !policy.retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := policy.delay(err, n, c.random.Float64())
+		c.logger.Warn(fmt.Sprintf("%s Tentativa %d de %d falhou (%s), nova tentativa em %v", label, n, policy.maxAttempts, entities.FailureKindOf(err), delay))
+
+		if slot.pause(ctx, delay) != nil {
+			return err
+		}
+	}
+}
+
+func (c *CrawlerService) retryPolicy(site config.CrawlerSiteConfig) retryPolicy {
+	return newRetryPolicy(c.cfg.Retry, site.Retry)
+}
+
+// deferredProduct product left for the second pass with the failure that
+// deferred it
+type deferredProduct struct {
+	product entities.Product
+	err     error
+}
+
+// secondPass products whose retryable failures outlasted their attempts,
+// crawled again once the rest of the run is over
+type secondPass struct {
+	mu       sync.Mutex
+	enabled  bool
+	products []deferredProduct
+}
+
+// add defers the product, returning false when the second pass is disabled or
+// already running
+func (s *secondPass) add(product entities.Product, err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.enabled {
+		return false
+	}
+	s.products = append(s.products, deferredProduct{product: product, err: err})
+
+	return true
+}
+
+// take returns the deferred products and stops accepting new ones
+func (s *secondPass) take() []deferredProduct {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	products := s.products
+	s.products = nil
+	s.enabled = false
+
+	return products
+}
+
+func (s *secondPass) reset(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.enabled = enabled
+	s.products = nil
+}
+
+// lockedRand rand.Rand safe for concurrent use by the crawler workers
+type lockedRand struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+func newLockedRand() *lockedRand {
+	return &lockedRand{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+func (r *lockedRand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rand.Float64()
+}
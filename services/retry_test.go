@@ -0,0 +1,63 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/JoaoLeal92/product-monitor-orchestrator/config"
+	"github.com/JoaoLeal92/product-monitor-orchestrator/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	policy := newRetryPolicy(
+		config.RetryConfig{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second},
+		config.RetryConfig{MaxAttempts: 4, RetryOn: []string{"blocked"}},
+	)
+
+	assert.Equal(t, 4, policy.maxAttempts)
+	assert.True(t, policy.retryable(&entities.CrawlerError{Kind: entities.FailureBlocked}))
+	assert.False(t, policy.retryable(&entities.CrawlerError{Kind: entities.FailureNetwork}))
+	assert.False(t, policy.retryable(entities.ErrDailyBudgetExhausted))
+	assert.False(t, policy.retryable(fmt.Errorf("%w: %s", entities.ErrLinkNotOfCrawler, "https://www.other-store.com/")))
+}
+
+func TestRetryPolicyDefaults(t *testing.T) {
+	policy := newRetryPolicy(config.RetryConfig{}, config.RetryConfig{})
+
+	assert.Equal(t, 1, policy.maxAttempts)
+	assert.True(t, policy.retryable(&entities.CrawlerError{Kind: entities.FailureTimeout}))
+	assert.True(t, policy.retryable(&entities.CrawlerError{Kind: entities.FailureNetwork}))
+	assert.False(t, policy.retryable(errors.New("exec: \"pipenv\": executable file not found in $PATH")))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := newRetryPolicy(config.RetryConfig{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}, config.RetryConfig{})
+
+	tests := map[string]struct {
+		attempt       int
+		random        float64
+		expectedDelay time.Duration
+	}{
+		"first-attempt-no-jitter":   {1, 0, 500 * time.Millisecond},
+		"first-attempt-full-jitter": {1, 1, time.Second},
+		"third-attempt":             {3, 0.5, 3 * time.Second},
+		"capped":                    {10, 1, 5 * time.Second},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expectedDelay, policy.backoff(testData.attempt, testData.random))
+		})
+	}
+}
+
+func TestRetryDelayHonoursRetryAfter(t *testing.T) {
+	policy := newRetryPolicy(config.RetryConfig{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}, config.RetryConfig{})
+
+	assert.Equal(t, time.Minute, policy.delay(&entities.CrawlerError{Kind: entities.FailureBlocked, RetryAfter: time.Minute}, 1, 0))
+	assert.Equal(t, time.Second, policy.delay(&entities.CrawlerError{Kind: entities.FailureBlocked, RetryAfter: time.Millisecond}, 1, 1))
+	assert.Equal(t, 500*time.Millisecond, policy.delay(&entities.CrawlerError{Kind: entities.FailureNetwork}, 1, 0))
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+)
+
+// RunStats counters of a crawler run
+type RunStats struct {
+	Crawled  int
+	Failed   int
+	TimedOut int
+	// Skipped products not crawled because their crawler ran out of its daily
+	// request budget or its circuit breaker was open
+	Skipped int
+}
+
+func (r RunStats) String() string {
+	return fmt.Sprintf("crawled=%d failed=%d timed_out=%d skipped=%d", r.Crawled, r.Failed, r.TimedOut, r.Skipped)
+}
+
+// runStatsCollector RunStats safe for concurrent use by the crawler workers
+type runStatsCollector struct {
+	mu    sync.Mutex
+	stats RunStats
+}
+
+func (r *runStatsCollector) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats = RunStats{}
+}
+
+func (r *runStatsCollector) add(update func(stats *RunStats)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	update(&r.stats)
+}
+
+func (r *runStatsCollector) snapshot() RunStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}